@@ -0,0 +1,54 @@
+// Copyright (C) 2017 go-nebulas authors
+//
+// This file is part of the go-nebulas library.
+//
+// the go-nebulas library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// the go-nebulas library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with the go-nebulas library.  If not, see <http://www.gnu.org/licenses/>.
+//
+
+package trie
+
+import (
+	"testing"
+
+	"github.com/alexlisong/go-nebulas/storage"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTrie_VerifyProof(t *testing.T) {
+	storage, _ := storage.NewMemoryStorage()
+	tr, _ := NewTrie(nil, storage, false)
+	_, err := tr.Put([]byte("key1a"), []byte("value1"))
+	assert.Nil(t, err)
+	_, err = tr.Put([]byte("key2b"), []byte("value2"))
+	assert.Nil(t, err)
+
+	proof, err := tr.Prove([]byte("key1a"))
+	assert.Nil(t, err)
+	assert.Nil(t, tr.Verify(tr.RootHash(), []byte("key1a"), proof))
+	assert.NotNil(t, tr.Verify(tr.RootHash(), []byte("key2b"), proof))
+
+	// a proof stopping before the leaf node proves nothing.
+	assert.NotNil(t, tr.Verify(tr.RootHash(), []byte("key1a"), proof[:len(proof)-1]))
+	assert.NotNil(t, tr.Verify(tr.RootHash(), []byte("key1a"), nil))
+
+	// keys shorter than the proved path run out of route in an extension or a branch node.
+	assert.NotNil(t, tr.Verify(tr.RootHash(), []byte("k"), proof))
+	assert.NotNil(t, tr.Verify(tr.RootHash(), []byte("key"), proof))
+
+	// malformed nodes are rejected.
+	forged := MerkleProof{[][]byte{nil, []byte("path"), []byte("next")}}
+	n, err := tr.createNode(forged[0])
+	assert.Nil(t, err)
+	assert.NotNil(t, tr.Verify(n.Hash, []byte("key1a"), forged))
+}
@@ -21,6 +21,7 @@ package core
 import (
 	"github.com/btcsuite/btcutil/base58"
 	"github.com/alexlisong/go-nebulas/crypto/hash"
+	"github.com/alexlisong/go-nebulas/crypto/keystore"
 	"github.com/alexlisong/go-nebulas/util/byteutils"
 )
 
@@ -138,6 +139,11 @@ func (a *Address) String() string {
 	return base58.Encode(a.address)
 }
 
+// ToHex returns address hex string
+func (a *Address) ToHex() string {
+	return byteutils.Hex(a.address)
+}
+
 // Equals compare two Address. True is equal, otherwise false.
 func (a *Address) Equals(b *Address) bool {
 	if a == nil {
@@ -154,6 +160,30 @@ func (a *Address) Type() AddressType {
 	return AddressType(a.address[AddressTypeIndex])
 }
 
+// IsZero check if the address data is all zero, such address has no key and is used for burns.
+func (a *Address) IsZero() bool {
+	if a == nil || len(a.address) != AddressLength {
+		return false
+	}
+	for _, b := range a.address[AddressTypeIndex+1 : AddressDataEnd] {
+		if b != 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// SupportsAlgorithm check if the address can sign with the algorithm.
+// account addresses are derived from secp256k1 public keys, contract addresses have no key.
+func (a *Address) SupportsAlgorithm(alg keystore.Algorithm) bool {
+	switch a.Type() {
+	case AccountAddress:
+		return alg == keystore.SECP256K1
+	default:
+		return false
+	}
+}
+
 // NewAddress create new #Address according to data bytes.
 func newAddress(t AddressType, args ...[]byte) (*Address, error) {
 	if len(args) == 0 {
@@ -245,3 +245,24 @@ func TestAddressGetType(t *testing.T) {
 		})
 	}
 }
+
+func mockZeroAddress(t AddressType) *Address {
+	buffer := make([]byte, AddressLength)
+	buffer[AddressPaddingIndex] = Padding
+	buffer[AddressTypeIndex] = byte(t)
+	copy(buffer[AddressDataEnd:], checkSum(buffer[:AddressDataEnd]))
+	return &Address{address: buffer}
+}
+
+func TestAddress_IsZero(t *testing.T) {
+	zero := mockZeroAddress(AccountAddress)
+	parsed, err := AddressParseFromBytes(zero.Bytes())
+	assert.Nil(t, err)
+	assert.True(t, parsed.IsZero())
+	assert.True(t, mockZeroAddress(ContractAddress).IsZero())
+
+	addr, _ := AddressParse("n1TV3sU6jyzR4rJ1D7jCAmtVGSntJagXZHC")
+	assert.False(t, addr.IsZero())
+	var nilAddr *Address
+	assert.False(t, nilAddr.IsZero())
+}
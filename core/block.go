@@ -828,6 +828,14 @@ func (block *Block) VerifyIntegrity(chainID uint32, consensus Consensus) error {
 			}).Debug("Failed to verify tx's integrity.")
 			return err
 		}
+		// a tx whose gasLimit is below its intrinsic gas can never execute, the pool rejects it too.
+		if err := tx.VerifyIntrinsicGas(); err != nil {
+			logging.VLog().WithFields(logrus.Fields{
+				"tx":  tx,
+				"err": err,
+			}).Debug("Failed to verify tx's intrinsic gas.")
+			return err
+		}
 	}
 
 	// verify block hash.
@@ -434,32 +434,32 @@ func TestBlock(t *testing.T) {
 				1,
 				Transactions{
 					&Transaction{
-						[]byte("123452"),
-						from1,
-						to1,
-						util.NewUint128(),
-						456,
-						1516464510,
-						&corepb.Data{Type: TxPayloadBinaryType, Payload: []byte("hello")},
-						1,
-						gasPrice,
-						gasLimit,
-						keystore.SECP256K1,
-						nil,
+						hash:      []byte("123452"),
+						from:      from1,
+						to:        to1,
+						value:     util.NewUint128(),
+						nonce:     456,
+						timestamp: 1516464510,
+						data:      &corepb.Data{Type: TxPayloadBinaryType, Payload: []byte("hello")},
+						chainID:   1,
+						gasPrice:  gasPrice,
+						gasLimit:  gasLimit,
+						alg:       keystore.SECP256K1,
+						sign:      nil,
 					},
 					&Transaction{
-						[]byte("123455"),
-						from2,
-						to2,
-						util.NewUint128(),
-						446,
-						1516464511,
-						&corepb.Data{Type: TxPayloadBinaryType, Payload: []byte("hllo")},
-						2,
-						gasPrice,
-						gasLimit,
-						keystore.SECP256K1,
-						nil,
+						hash:      []byte("123455"),
+						from:      from2,
+						to:        to2,
+						value:     util.NewUint128(),
+						nonce:     446,
+						timestamp: 1516464511,
+						data:      &corepb.Data{Type: TxPayloadBinaryType, Payload: []byte("hllo")},
+						chainID:   2,
+						gasPrice:  gasPrice,
+						gasLimit:  gasLimit,
+						alg:       keystore.SECP256K1,
+						sign:      nil,
 					},
 				},
 				dag.NewDag(),
@@ -635,6 +635,26 @@ func TestBlockVerifyIntegrity(t *testing.T) {
 	assert.NotNil(t, block.VerifyIntegrity(bc.ChainID(), bc.ConsensusHandler()))
 }
 
+func TestBlockVerifyIntrinsicGas(t *testing.T) {
+	neb := testNeb(t)
+	bc := neb.chain
+	ks := keystore.DefaultKS
+	from := mockAddress()
+	key, err := ks.GetUnlocked(from.String())
+	assert.Nil(t, err)
+	signature, err := crypto.NewSignature(keystore.SECP256K1)
+	assert.Nil(t, err)
+	signature.InitSign(key.(keystore.PrivateKey))
+	block, err := bc.NewBlock(from)
+	assert.Nil(t, err)
+	tx1, _ := NewTransaction(bc.ChainID(), from, from, util.NewUint128(), 1, TxPayloadBinaryType, []byte("nas"), TransactionGasPrice, MinGasCountPerTransaction)
+	tx1.Sign(signature)
+	block.transactions = append(block.transactions, tx1)
+	block.Seal()
+	block.Sign(signature)
+	assert.Equal(t, ErrGasLimitBelowIntrinsic, block.VerifyIntegrity(bc.ChainID(), bc.ConsensusHandler()))
+}
+
 func TestBlockVerifyDupTx(t *testing.T) {
 	neb := testNeb(t)
 	bc := neb.chain
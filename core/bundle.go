@@ -0,0 +1,98 @@
+// Copyright (C) 2017 go-nebulas authors
+//
+// This file is part of the go-nebulas library.
+//
+// the go-nebulas library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// the go-nebulas library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with the go-nebulas library.  If not, see <http://www.gnu.org/licenses/>.
+//
+
+package core
+
+import (
+	"github.com/alexlisong/go-nebulas/crypto"
+	"github.com/alexlisong/go-nebulas/crypto/hash"
+	"github.com/alexlisong/go-nebulas/crypto/keystore"
+	"github.com/alexlisong/go-nebulas/util/byteutils"
+)
+
+// Bundle is an ordered set of signed txs that must be included together and in order.
+type Bundle struct {
+	txs Transactions
+
+	// Signature over CommitmentHash
+	alg  keystore.Algorithm
+	sign byteutils.Hash
+}
+
+// NewBundle create a bundle of txs, the order of txs is committed.
+func NewBundle(txs Transactions) *Bundle {
+	return &Bundle{txs: txs}
+}
+
+// Transactions return txs in bundle
+func (b *Bundle) Transactions() Transactions {
+	return b.txs
+}
+
+// CommitmentHash return the hash over the count and the ordered hashes of txs.
+func (b *Bundle) CommitmentHash() (byteutils.Hash, error) {
+	args := [][]byte{byteutils.FromUint64(uint64(len(b.txs)))}
+	for _, tx := range b.txs {
+		if tx == nil || len(tx.hash) == 0 {
+			return nil, ErrInvalidTransactionHash
+		}
+		args = append(args, tx.hash)
+	}
+	return hash.Sha3256(args...), nil
+}
+
+// Sign sign the commitment of bundle with the key.
+func (b *Bundle) Sign(key keystore.PrivateKey, alg keystore.Algorithm) error {
+	if key == nil {
+		return ErrNilArgument
+	}
+	commitment, err := b.CommitmentHash()
+	if err != nil {
+		return err
+	}
+	signature, err := crypto.NewSignature(alg)
+	if err != nil {
+		return err
+	}
+	if err := signature.InitSign(key); err != nil {
+		return err
+	}
+	sign, err := signature.Sign(commitment)
+	if err != nil {
+		return err
+	}
+	b.alg = alg
+	b.sign = sign
+	return nil
+}
+
+// VerifyCommitment check the commitment of current txs order is signed by signer.
+func (b *Bundle) VerifyCommitment(signer *Address) error {
+	commitment, err := b.CommitmentHash()
+	if err != nil {
+		return err
+	}
+	addr, err := RecoverSignerFromSignature(b.alg, commitment, b.sign)
+	if err != nil {
+		return err
+	}
+	if !addr.Equals(signer) {
+		return ErrInvalidBundleSigner
+	}
+	return nil
+}
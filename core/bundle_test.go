@@ -0,0 +1,49 @@
+// Copyright (C) 2017 go-nebulas authors
+//
+// This file is part of the go-nebulas library.
+//
+// the go-nebulas library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// the go-nebulas library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with the go-nebulas library.  If not, see <http://www.gnu.org/licenses/>.
+//
+
+package core
+
+import (
+	"testing"
+
+	"github.com/alexlisong/go-nebulas/crypto/keystore"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBundle_VerifyCommitment(t *testing.T) {
+	txs := mockSignedTransactions(t, 3)
+	submitter := mockAddress()
+	key, err := keystore.DefaultKS.GetUnlocked(submitter.String())
+	assert.Nil(t, err)
+
+	bundle := NewBundle(txs)
+	assert.Nil(t, bundle.Sign(key.(keystore.PrivateKey), keystore.SECP256K1))
+	assert.Nil(t, bundle.VerifyCommitment(submitter))
+	assert.Equal(t, ErrInvalidBundleSigner, bundle.VerifyCommitment(mockAddress()))
+
+	// reordered
+	reordered := &Bundle{txs: Transactions{txs[1], txs[0], txs[2]}, alg: bundle.alg, sign: bundle.sign}
+	assert.NotNil(t, reordered.VerifyCommitment(submitter))
+
+	// partially included
+	partial := &Bundle{txs: txs[:2], alg: bundle.alg, sign: bundle.sign}
+	assert.NotNil(t, partial.VerifyCommitment(submitter))
+
+	unsigned := NewBundle(Transactions{mockNormalTransaction(1, 1)})
+	assert.Equal(t, ErrInvalidTransactionHash, unsigned.Sign(key.(keystore.PrivateKey), keystore.SECP256K1))
+}
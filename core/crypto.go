@@ -19,10 +19,36 @@
 package core
 
 import (
+	"sync"
+
 	"github.com/alexlisong/go-nebulas/crypto"
 	"github.com/alexlisong/go-nebulas/crypto/keystore"
 )
 
+// algorithmStats counts tx signature verifications per algorithm
+var algorithmStats = struct {
+	sync.Mutex
+	counts map[keystore.Algorithm]uint64
+}{counts: make(map[keystore.Algorithm]uint64)}
+
+func recordAlgorithm(alg keystore.Algorithm) {
+	algorithmStats.Lock()
+	defer algorithmStats.Unlock()
+	algorithmStats.counts[alg]++
+}
+
+// AlgorithmStats return the count of tx signature verifications per supported algorithm,
+// txs with an unsupported algorithm are rejected without being counted.
+func AlgorithmStats() map[keystore.Algorithm]uint64 {
+	algorithmStats.Lock()
+	defer algorithmStats.Unlock()
+	stats := make(map[keystore.Algorithm]uint64, len(algorithmStats.counts))
+	for alg, count := range algorithmStats.counts {
+		stats[alg] = count
+	}
+	return stats
+}
+
 // RecoverSignerFromSignature return address who signs the signature
 func RecoverSignerFromSignature(alg keystore.Algorithm, plainText []byte, cipherText []byte) (*Address, error) {
 	signature, err := crypto.NewSignature(alg)
@@ -178,7 +178,7 @@ func NewGenesisBlock(conf *corepb.Genesis, chain *BlockChain) (*Block, error) {
 		return nil, err
 	}
 	genesisBlock.transactions = append(genesisBlock.transactions, declarationTx)
-	if err := genesisBlock.worldState.PutTx(declarationTx.hash, txBytes); err != nil {
+	if err := genesisBlock.worldState.PutTx(declarationTx.TrieKey(), txBytes); err != nil {
 		return nil, err
 	}
 
@@ -0,0 +1,111 @@
+// Copyright (C) 2017 go-nebulas authors
+//
+// This file is part of the go-nebulas library.
+//
+// the go-nebulas library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// the go-nebulas library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with the go-nebulas library.  If not, see <http://www.gnu.org/licenses/>.
+//
+
+package core
+
+import (
+	"github.com/alexlisong/go-nebulas/crypto/hash"
+	"github.com/alexlisong/go-nebulas/util/byteutils"
+)
+
+// merkleLeaf hash a leaf, leaves and inner nodes are hashed with different prefixes,
+// so a root can't be forged from inner nodes.
+func merkleLeaf(h []byte) []byte {
+	return hash.Sha3256([]byte{0x0}, h)
+}
+
+func merkleInner(left, right []byte) []byte {
+	return hash.Sha3256([]byte{0x1}, left, right)
+}
+
+// merkleRoot return the root of the binary merkle tree over hashes, shared by txs and receipts,
+// an odd node is promoted to the next level without hashing.
+func merkleRoot(hashes [][]byte) byteutils.Hash {
+	if len(hashes) == 0 {
+		return hash.Sha3256()
+	}
+	level := merkleLeaves(hashes)
+	for len(level) > 1 {
+		level = merkleParents(level)
+	}
+	return level[0]
+}
+
+func merkleLeaves(hashes [][]byte) [][]byte {
+	level := make([][]byte, len(hashes))
+	for i, h := range hashes {
+		level[i] = merkleLeaf(h)
+	}
+	return level
+}
+
+// merkleParents hash each pair of nodes into the next level, an odd node is promoted.
+func merkleParents(level [][]byte) [][]byte {
+	next := make([][]byte, 0, (len(level)+1)/2)
+	for i := 0; i < len(level); i += 2 {
+		if i+1 == len(level) {
+			next = append(next, level[i])
+			continue
+		}
+		next = append(next, merkleInner(level[i], level[i+1]))
+	}
+	return next
+}
+
+// merkleProof return the sibling of the index-th leaf at each level up to the root,
+// nil for the levels where the node is the odd one promoted.
+func merkleProof(hashes [][]byte, index int) ([]byteutils.Hash, error) {
+	if index < 0 || index >= len(hashes) {
+		return nil, ErrInvalidArgument
+	}
+	level := merkleLeaves(hashes)
+	var proof []byteutils.Hash
+	for len(level) > 1 {
+		var sibling byteutils.Hash
+		if index^1 < len(level) {
+			sibling = level[index^1]
+		}
+		proof = append(proof, sibling)
+		level = merkleParents(level)
+		index /= 2
+	}
+	return proof, nil
+}
+
+// verifyMerkleProof check proof made by merkleProof proves h is the index-th leaf under root.
+func verifyMerkleProof(h byteutils.Hash, proof []byteutils.Hash, index int, root byteutils.Hash) bool {
+	if len(h) == 0 || len(root) == 0 || index < 0 {
+		return false
+	}
+	node := merkleLeaf(h)
+	for _, sibling := range proof {
+		switch {
+		case index%2 == 1:
+			// a right node always has a left sibling.
+			if sibling == nil {
+				return false
+			}
+			node = merkleInner(sibling, node)
+		case sibling != nil:
+			node = merkleInner(node, sibling)
+		}
+		index /= 2
+	}
+	// a shorter proof leaves the node below the root.
+	return index == 0 && root.Equals(node)
+}
@@ -109,18 +109,26 @@ func (m *Data) GetPayload() []byte {
 }
 
 type Transaction struct {
-	Hash      []byte `protobuf:"bytes,1,opt,name=hash,proto3" json:"hash,omitempty"`
-	From      []byte `protobuf:"bytes,2,opt,name=from,proto3" json:"from,omitempty"`
-	To        []byte `protobuf:"bytes,3,opt,name=to,proto3" json:"to,omitempty"`
-	Value     []byte `protobuf:"bytes,4,opt,name=value,proto3" json:"value,omitempty"`
-	Nonce     uint64 `protobuf:"varint,5,opt,name=nonce,proto3" json:"nonce,omitempty"`
-	Timestamp int64  `protobuf:"varint,6,opt,name=timestamp,proto3" json:"timestamp,omitempty"`
-	Data      *Data  `protobuf:"bytes,7,opt,name=data" json:"data,omitempty"`
-	ChainId   uint32 `protobuf:"varint,8,opt,name=chain_id,json=chainId,proto3" json:"chain_id,omitempty"`
-	GasPrice  []byte `protobuf:"bytes,9,opt,name=gas_price,json=gasPrice,proto3" json:"gas_price,omitempty"`
-	GasLimit  []byte `protobuf:"bytes,10,opt,name=gas_limit,json=gasLimit,proto3" json:"gas_limit,omitempty"`
-	Alg       uint32 `protobuf:"varint,11,opt,name=alg,proto3" json:"alg,omitempty"`
-	Sign      []byte `protobuf:"bytes,12,opt,name=sign,proto3" json:"sign,omitempty"`
+	Hash            []byte `protobuf:"bytes,1,opt,name=hash,proto3" json:"hash,omitempty"`
+	From            []byte `protobuf:"bytes,2,opt,name=from,proto3" json:"from,omitempty"`
+	To              []byte `protobuf:"bytes,3,opt,name=to,proto3" json:"to,omitempty"`
+	Value           []byte `protobuf:"bytes,4,opt,name=value,proto3" json:"value,omitempty"`
+	Nonce           uint64 `protobuf:"varint,5,opt,name=nonce,proto3" json:"nonce,omitempty"`
+	Timestamp       int64  `protobuf:"varint,6,opt,name=timestamp,proto3" json:"timestamp,omitempty"`
+	Data            *Data  `protobuf:"bytes,7,opt,name=data" json:"data,omitempty"`
+	ChainId         uint32 `protobuf:"varint,8,opt,name=chain_id,json=chainId,proto3" json:"chain_id,omitempty"`
+	GasPrice        []byte `protobuf:"bytes,9,opt,name=gas_price,json=gasPrice,proto3" json:"gas_price,omitempty"`
+	GasLimit        []byte `protobuf:"bytes,10,opt,name=gas_limit,json=gasLimit,proto3" json:"gas_limit,omitempty"`
+	Alg             uint32 `protobuf:"varint,11,opt,name=alg,proto3" json:"alg,omitempty"`
+	Sign            []byte `protobuf:"bytes,12,opt,name=sign,proto3" json:"sign,omitempty"`
+	Salt            []byte `protobuf:"bytes,13,opt,name=salt,proto3" json:"salt,omitempty"`
+	TipRecipient    []byte `protobuf:"bytes,14,opt,name=tip_recipient,json=tipRecipient,proto3" json:"tip_recipient,omitempty"`
+	EphemeralPubKey []byte `protobuf:"bytes,15,opt,name=ephemeral_pub_key,json=ephemeralPubKey,proto3" json:"ephemeral_pub_key,omitempty"`
+	PowNonce        uint64 `protobuf:"varint,16,opt,name=pow_nonce,json=powNonce,proto3" json:"pow_nonce,omitempty"`
+	HashAlg         uint32 `protobuf:"varint,17,opt,name=hash_alg,json=hashAlg,proto3" json:"hash_alg,omitempty"`
+	ValidFrom       int64  `protobuf:"varint,18,opt,name=valid_from,json=validFrom,proto3" json:"valid_from,omitempty"`
+	Deadline        int64  `protobuf:"varint,19,opt,name=deadline,proto3" json:"deadline,omitempty"`
+	ForkMarker      []byte `protobuf:"bytes,20,opt,name=fork_marker,json=forkMarker,proto3" json:"fork_marker,omitempty"`
 }
 
 func (m *Transaction) Reset()                    { *m = Transaction{} }
@@ -212,6 +220,62 @@ func (m *Transaction) GetSign() []byte {
 	return nil
 }
 
+func (m *Transaction) GetSalt() []byte {
+	if m != nil {
+		return m.Salt
+	}
+	return nil
+}
+
+func (m *Transaction) GetTipRecipient() []byte {
+	if m != nil {
+		return m.TipRecipient
+	}
+	return nil
+}
+
+func (m *Transaction) GetEphemeralPubKey() []byte {
+	if m != nil {
+		return m.EphemeralPubKey
+	}
+	return nil
+}
+
+func (m *Transaction) GetPowNonce() uint64 {
+	if m != nil {
+		return m.PowNonce
+	}
+	return 0
+}
+
+func (m *Transaction) GetHashAlg() uint32 {
+	if m != nil {
+		return m.HashAlg
+	}
+	return 0
+}
+
+func (m *Transaction) GetValidFrom() int64 {
+	if m != nil {
+		return m.ValidFrom
+	}
+	return 0
+}
+
+func (m *Transaction) GetDeadline() int64 {
+	if m != nil {
+		return m.Deadline
+	}
+	return 0
+}
+
+func (m *Transaction) GetForkMarker() []byte {
+	if m != nil {
+		return m.ForkMarker
+	}
+	return nil
+}
+
 type BlockHeader struct {
 	Hash          []byte                     `protobuf:"bytes,1,opt,name=hash,proto3" json:"hash,omitempty"`
 	ParentHash    []byte                     `protobuf:"bytes,2,opt,name=parent_hash,json=parentHash,proto3" json:"parent_hash,omitempty"`
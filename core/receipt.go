@@ -0,0 +1,50 @@
+// Copyright (C) 2017 go-nebulas authors
+//
+// This file is part of the go-nebulas library.
+//
+// the go-nebulas library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// the go-nebulas library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with the go-nebulas library.  If not, see <http://www.gnu.org/licenses/>.
+//
+
+package core
+
+import (
+	"github.com/alexlisong/go-nebulas/util/byteutils"
+)
+
+// Receipts is the receipt hashes of a batch of txs, in the order of the txs.
+type Receipts []byteutils.Hash
+
+// Root return the merkle root over the receipt hashes, built the same way as Transactions.MerkleRoot.
+func (rs Receipts) Root() byteutils.Hash {
+	hashes := make([][]byte, len(rs))
+	for i, r := range rs {
+		hashes[i] = r
+	}
+	return merkleRoot(hashes)
+}
+
+// Proof return the sibling hashes from the receipt leaf at index up to the root,
+// a nil sibling marks a level where the node is promoted without hashing.
+func (rs Receipts) Proof(index int) ([]byteutils.Hash, error) {
+	hashes := make([][]byte, len(rs))
+	for i, r := range rs {
+		hashes[i] = r
+	}
+	return merkleProof(hashes, index)
+}
+
+// VerifyReceiptInclusion check the proof returned by Receipts.Proof proves receiptHash is at index under root.
+func VerifyReceiptInclusion(receiptHash byteutils.Hash, proof []byteutils.Hash, index int, root byteutils.Hash) bool {
+	return verifyMerkleProof(receiptHash, proof, index, root)
+}
@@ -0,0 +1,88 @@
+// Copyright (C) 2017 go-nebulas authors
+//
+// This file is part of the go-nebulas library.
+//
+// the go-nebulas library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// the go-nebulas library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with the go-nebulas library.  If not, see <http://www.gnu.org/licenses/>.
+//
+
+package core
+
+import (
+	"testing"
+
+	"github.com/alexlisong/go-nebulas/crypto/hash"
+	"github.com/alexlisong/go-nebulas/util/byteutils"
+	"github.com/stretchr/testify/assert"
+)
+
+func mockReceipts(n int) Receipts {
+	rs := make(Receipts, n)
+	for i := range rs {
+		rs[i] = hash.Sha3256([]byte("receipt"), byteutils.FromUint64(uint64(i)))
+	}
+	return rs
+}
+
+func TestReceipts_Root(t *testing.T) {
+	assert.Equal(t, Transactions{}.MerkleRoot(), Receipts{}.Root())
+
+	rs := mockReceipts(5)
+	txs := make(Transactions, len(rs))
+	for i, r := range rs {
+		txs[i] = &Transaction{hash: r}
+	}
+	assert.Equal(t, txs.MerkleRoot(), rs.Root())
+	// both trees share the proof format too.
+	for i := range rs {
+		txProof, err := txs.MerkleProof(i)
+		assert.Nil(t, err)
+		proof, err := rs.Proof(i)
+		assert.Nil(t, err)
+		assert.Equal(t, txProof, proof)
+		assert.True(t, VerifyInclusion(rs[i], proof, i, rs.Root()))
+	}
+
+	reordered := Receipts{rs[1], rs[0], rs[2], rs[3], rs[4]}
+	assert.NotEqual(t, rs.Root(), reordered.Root())
+}
+
+func TestVerifyReceiptInclusion(t *testing.T) {
+	for n := 1; n <= 9; n++ {
+		rs := mockReceipts(n)
+		root := rs.Root()
+		for i := range rs {
+			proof, err := rs.Proof(i)
+			assert.Nil(t, err)
+			assert.True(t, VerifyReceiptInclusion(rs[i], proof, i, root), "n %d index %d", n, i)
+			if n > 1 {
+				assert.False(t, VerifyReceiptInclusion(rs[i], proof, (i+1)%n, root), "n %d index %d", n, i)
+				assert.False(t, VerifyReceiptInclusion(rs[(i+1)%n], proof, i, root), "n %d index %d", n, i)
+			}
+		}
+	}
+
+	rs := mockReceipts(4)
+	proof, err := rs.Proof(2)
+	assert.Nil(t, err)
+	forged := append([]byteutils.Hash{}, proof...)
+	forged[0] = rs[0]
+	assert.False(t, VerifyReceiptInclusion(rs[2], forged, 2, rs.Root()))
+	assert.False(t, VerifyReceiptInclusion(rs[2], proof, 2, mockReceipts(5).Root()))
+	assert.False(t, VerifyReceiptInclusion(nil, proof, 2, rs.Root()))
+
+	_, err = rs.Proof(4)
+	assert.Equal(t, ErrInvalidArgument, err)
+	_, err = rs.Proof(-1)
+	assert.Equal(t, ErrInvalidArgument, err)
+}
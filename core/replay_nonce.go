@@ -0,0 +1,111 @@
+// Copyright (C) 2017 go-nebulas authors
+//
+// This file is part of the go-nebulas library.
+//
+// the go-nebulas library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// the go-nebulas library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with the go-nebulas library.  If not, see <http://www.gnu.org/licenses/>.
+//
+
+package core
+
+import (
+	"sync"
+
+	"github.com/alexlisong/go-nebulas/crypto/hash"
+	"github.com/alexlisong/go-nebulas/util/byteutils"
+)
+
+// ReplaySaltLength is the length of the random salt of salted nonce txs.
+const ReplaySaltLength = 32
+
+// Nonce modes
+const (
+	TxNonceModeCounter = "counter"
+	TxNonceModeSalt    = "salt"
+)
+
+// NonceMode return how the tx is protected against replay,
+// by the account counter nonce or by a random salt checked against a seen set.
+func (tx *Transaction) NonceMode() string {
+	if len(tx.salt) > 0 {
+		return TxNonceModeSalt
+	}
+	return TxNonceModeCounter
+}
+
+// Salt return the replay salt, nil in counter nonce mode
+func (tx *Transaction) Salt() []byte {
+	return tx.salt
+}
+
+// SetReplaySalt switch the tx to salted nonce mode, the salt is hashed into the tx,
+// so it must be set before signing.
+func (tx *Transaction) SetReplaySalt(salt []byte) error {
+	if tx.frozen {
+		return ErrTransactionFrozen
+	}
+	if len(salt) != ReplaySaltLength {
+		return ErrInvalidReplaySalt
+	}
+	tx.salt = make([]byte, ReplaySaltLength)
+	copy(tx.salt, salt)
+	tx.rawBytes = nil
+	tx.cachedHash = nil
+	return nil
+}
+
+// ReplayNonce return the salted nonce of the tx, it's derived from sender and salt.
+// return nil in counter nonce mode.
+func (tx *Transaction) ReplayNonce() byteutils.Hash {
+	if len(tx.salt) == 0 {
+		return nil
+	}
+	return hash.Sha3256(tx.from.Bytes(), tx.salt)
+}
+
+// SeenNonceSet records the salted nonces already accepted.
+type SeenNonceSet struct {
+	mu     sync.Mutex
+	nonces map[byteutils.HexHash]bool
+}
+
+// NewSeenNonceSet create a empty seen nonce set.
+func NewSeenNonceSet() *SeenNonceSet {
+	return &SeenNonceSet{
+		nonces: make(map[byteutils.HexHash]bool),
+	}
+}
+
+// CheckAndAdd return true and record the nonce if it's not seen before,
+// return false if the nonce is replayed.
+func (s *SeenNonceSet) CheckAndAdd(nonce byteutils.Hash) bool {
+	if len(nonce) == 0 {
+		return false
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	key := nonce.Hex()
+	if s.nonces[key] {
+		return false
+	}
+	s.nonces[key] = true
+	return true
+}
+
+// Len return the count of seen nonces.
+func (s *SeenNonceSet) Len() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.nonces)
+}
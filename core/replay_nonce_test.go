@@ -0,0 +1,94 @@
+// Copyright (C) 2017 go-nebulas authors
+//
+// This file is part of the go-nebulas library.
+//
+// the go-nebulas library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// the go-nebulas library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with the go-nebulas library.  If not, see <http://www.gnu.org/licenses/>.
+//
+
+package core
+
+import (
+	"bytes"
+	"crypto/rand"
+	"testing"
+
+	"github.com/alexlisong/go-nebulas/core/pb"
+	"github.com/gogo/protobuf/proto"
+	"github.com/stretchr/testify/assert"
+)
+
+func mockReplaySalt() []byte {
+	salt := make([]byte, ReplaySaltLength)
+	rand.Read(salt)
+	return salt
+}
+
+func TestTransaction_ReplaySalt(t *testing.T) {
+	tx := mockNormalTransaction(1, 0)
+	assert.Equal(t, TxNonceModeCounter, tx.NonceMode())
+	assert.Nil(t, tx.ReplayNonce())
+	counterHash, _ := tx.calHash()
+
+	assert.Equal(t, ErrInvalidReplaySalt, tx.SetReplaySalt([]byte{1, 2, 3}))
+	salt := mockReplaySalt()
+	assert.Nil(t, tx.SetReplaySalt(salt))
+	assert.Equal(t, TxNonceModeSalt, tx.NonceMode())
+	assert.Equal(t, 32, len(tx.ReplayNonce()))
+
+	// salt is hashed into tx.
+	saltedHash, _ := tx.calHash()
+	assert.NotEqual(t, counterHash, saltedHash)
+
+	assert.Nil(t, signTx(tx))
+	assert.Nil(t, tx.VerifyIntegrity(1))
+	pbTx, err := tx.ToProto()
+	assert.Nil(t, err)
+	ir, _ := proto.Marshal(pbTx)
+	msg := new(corepb.Transaction)
+	assert.Nil(t, proto.Unmarshal(ir, msg))
+	decoded := new(Transaction)
+	assert.Nil(t, decoded.FromProto(msg))
+	assert.True(t, bytes.Equal(salt, decoded.Salt()))
+	assert.Nil(t, decoded.VerifyIntegrity(1))
+
+	// tampered salt breaks hash.
+	decoded.salt = mockReplaySalt()
+	assert.Equal(t, ErrInvalidTransactionHash, decoded.VerifyIntegrity(1))
+
+	msg.Salt = []byte{1}
+	assert.Equal(t, ErrInvalidReplaySalt, new(Transaction).FromProto(msg))
+}
+
+func TestSeenNonceSet_CheckAndAdd(t *testing.T) {
+	set := NewSeenNonceSet()
+	tx1 := mockNormalTransaction(1, 0)
+	tx1.SetReplaySalt(mockReplaySalt())
+	tx2 := mockNormalTransaction(1, 0)
+	tx2.from = tx1.from
+	tx2.SetReplaySalt(mockReplaySalt())
+
+	assert.True(t, set.CheckAndAdd(tx1.ReplayNonce()))
+	assert.True(t, set.CheckAndAdd(tx2.ReplayNonce()))
+	assert.False(t, set.CheckAndAdd(tx1.ReplayNonce()))
+
+	// same salt from another sender is another nonce.
+	tx3 := mockNormalTransaction(1, 0)
+	tx3.SetReplaySalt(tx1.Salt())
+	assert.True(t, set.CheckAndAdd(tx3.ReplayNonce()))
+	assert.False(t, set.CheckAndAdd(tx3.ReplayNonce()))
+
+	// counter nonce txs have no replay nonce.
+	assert.False(t, set.CheckAndAdd(mockNormalTransaction(1, 1).ReplayNonce()))
+	assert.Equal(t, 3, set.Len())
+}
@@ -0,0 +1,94 @@
+// Copyright (C) 2017 go-nebulas authors
+//
+// This file is part of the go-nebulas library.
+//
+// the go-nebulas library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// the go-nebulas library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with the go-nebulas library.  If not, see <http://www.gnu.org/licenses/>.
+//
+
+package core
+
+import (
+	"github.com/alexlisong/go-nebulas/crypto/keystore"
+)
+
+// TxHashVersion is the set of fields covered by the tx hash.
+type TxHashVersion int
+
+// Tx hash versions
+const (
+	// TxHashV1 only covers the original fields.
+	TxHashV1 TxHashVersion = iota + 1
+	// TxHashV2 also covers salt, tip recipient, ephemeral public key, pow nonce, hash algorithm,
+	// validFrom, deadline and fork marker when set.
+	TxHashV2
+)
+
+// Ruleset is the fork-specific validation rules of txs.
+type Ruleset struct {
+	Name              string
+	Algorithms        []keystore.Algorithm
+	RequireReplaySalt bool
+	HashVersion       TxHashVersion
+}
+
+// Rulesets
+var (
+	PreForkRuleset = &Ruleset{
+		Name:        "pre-fork",
+		Algorithms:  []keystore.Algorithm{keystore.SECP256K1},
+		HashVersion: TxHashV1,
+	}
+	PostForkRuleset = &Ruleset{
+		Name:              "post-fork",
+		Algorithms:        []keystore.Algorithm{keystore.SECP256K1},
+		RequireReplaySalt: true,
+		HashVersion:       TxHashV2,
+	}
+)
+
+// AllowsAlgorithm check if the ruleset allows txs signed with the algorithm.
+func (rs *Ruleset) AllowsAlgorithm(alg keystore.Algorithm) bool {
+	for _, v := range rs.Algorithms {
+		if v == alg {
+			return true
+		}
+	}
+	return false
+}
+
+// HashVersion return the lowest hash version covering all the fields set in tx.
+func (tx *Transaction) HashVersion() TxHashVersion {
+	if len(tx.salt) > 0 || tx.tipRecipient != nil || len(tx.ephemeralPubKey) > 0 || tx.powNonce != 0 || tx.hashAlg != TxHashSha3256 ||
+		tx.validFrom != 0 || tx.deadline != 0 || len(tx.forkMarker) > 0 {
+		return TxHashV2
+	}
+	return TxHashV1
+}
+
+// VerifyUnderRuleset verify the tx hash and sign under the rules of rs instead of the active ones.
+func (tx *Transaction) VerifyUnderRuleset(rs *Ruleset) error {
+	if !rs.AllowsAlgorithm(tx.alg) {
+		return ErrAlgorithmNotInRuleset
+	}
+	if rs.RequireReplaySalt && tx.NonceMode() != TxNonceModeSalt {
+		return ErrMissingReplaySalt
+	}
+	if tx.HashVersion() > rs.HashVersion {
+		return ErrUnsupportedHashVersion
+	}
+	if err := tx.ValidateStoredHash(); err != nil {
+		return err
+	}
+	return tx.verifySign()
+}
@@ -0,0 +1,48 @@
+// Copyright (C) 2017 go-nebulas authors
+//
+// This file is part of the go-nebulas library.
+//
+// the go-nebulas library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// the go-nebulas library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with the go-nebulas library.  If not, see <http://www.gnu.org/licenses/>.
+//
+
+package core
+
+import (
+	"testing"
+
+	"github.com/alexlisong/go-nebulas/crypto/keystore"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTransaction_VerifyUnderRuleset(t *testing.T) {
+	legacy := mockNormalTransaction(1, 1)
+	assert.Nil(t, signTx(legacy))
+	assert.Equal(t, TxHashV1, legacy.HashVersion())
+	assert.Nil(t, legacy.VerifyUnderRuleset(PreForkRuleset))
+	assert.Equal(t, ErrMissingReplaySalt, legacy.VerifyUnderRuleset(PostForkRuleset))
+
+	salted := mockNormalTransaction(1, 1)
+	assert.Nil(t, salted.SetReplaySalt(mockReplaySalt()))
+	assert.Nil(t, signTx(salted))
+	assert.Equal(t, TxHashV2, salted.HashVersion())
+	assert.Equal(t, ErrUnsupportedHashVersion, salted.VerifyUnderRuleset(PreForkRuleset))
+	assert.Nil(t, salted.VerifyUnderRuleset(PostForkRuleset))
+
+	noAlg := &Ruleset{Name: "none", HashVersion: TxHashV2}
+	assert.False(t, noAlg.AllowsAlgorithm(keystore.SECP256K1))
+	assert.Equal(t, ErrAlgorithmNotInRuleset, salted.VerifyUnderRuleset(noAlg))
+
+	salted.nonce++
+	assert.Equal(t, ErrHashMismatch, salted.VerifyUnderRuleset(PostForkRuleset))
+}
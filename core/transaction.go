@@ -19,20 +19,29 @@
 package core
 
 import (
+	"bytes"
+	"encoding/binary"
 	"errors"
 	"fmt"
+	"math"
+	"math/big"
+	"sort"
 	"time"
 
 	"github.com/alexlisong/go-nebulas/crypto/sha3"
 
 	"github.com/alexlisong/go-nebulas/core/state"
 
+
 	"encoding/json"
 
+	"github.com/btcsuite/btcutil/base58"
 	"github.com/gogo/protobuf/proto"
 	"github.com/alexlisong/go-nebulas/core/pb"
 	"github.com/alexlisong/go-nebulas/crypto"
+	"github.com/alexlisong/go-nebulas/crypto/hash"
 	"github.com/alexlisong/go-nebulas/crypto/keystore"
+	"github.com/alexlisong/go-nebulas/crypto/keystore/secp256k1"
 	"github.com/alexlisong/go-nebulas/util"
 	"github.com/alexlisong/go-nebulas/util/byteutils"
 	"github.com/alexlisong/go-nebulas/util/logging"
@@ -65,10 +74,49 @@ var (
 	// MaxDataBinPayloadLength Max data length in binary transaction
 	MaxDataBinPayloadLength = 64
 
+	// MaxTransactionPreimageSize max bytes hashed into a tx hash, checked by VerifyIntegrity before hashing
+	MaxTransactionPreimageSize = MaxDataPayLoadLength + 1024
+
 	// MaxEventErrLength Max error length in event
 	MaxEventErrLength = 256
+
+	// TransactionsPerBlockEstimate estimated count of txs packed in one block, used by confirmation estimation
+	TransactionsPerBlockEstimate = 1000
+
+	// CancelGasPriceBumpPercent gasPrice of a cancel tx is higher than the cancelled tx by this percent
+	CancelGasPriceBumpPercent int64 = 10
+
+	// TransactionFutureSkew max time a tx timestamp can be ahead of local time in VerifyTimestamp
+	TransactionFutureSkew = 15 * time.Second
 )
 
+// txHashDomain is mixed into tx hashes of subchains, empty on the main chain
+var txHashDomain []byte
+
+// SetTxHashDomain set the domain tag of tx hashes, subchains set it at init before any tx is hashed,
+// txs hashed under one domain don't verify under another.
+func SetTxHashDomain(domain []byte) {
+	txHashDomain = make([]byte, len(domain))
+	copy(txHashDomain, domain)
+}
+
+// TxHashDomain return the domain tag of tx hashes
+func TxHashDomain() []byte {
+	return txHashDomain
+}
+
+// SigningAuditHook is called after each tx is signed, with the address of the signing key and the signing time.
+type SigningAuditHook func(tx *Transaction, signer *Address, at time.Time)
+
+// signingAuditHook is called by Sign, nil for no-op
+var signingAuditHook SigningAuditHook
+
+// SetSigningAuditHook set the hook called after each successful Sign, nil to disable,
+// it's set at init before any tx is signed.
+func SetSigningAuditHook(hook SigningAuditHook) {
+	signingAuditHook = hook
+}
+
 // TransactionEvent transaction event
 type TransactionEvent struct {
 	Hash    string `json:"hash"`
@@ -89,10 +137,28 @@ type Transaction struct {
 	chainID   uint32
 	gasPrice  *util.Uint128
 	gasLimit  *util.Uint128
+	salt      []byte // random replay salt, replaces the counter nonce when set
+
+	tipRecipient    *Address        // receiver of the tip, block producer when empty
+	ephemeralPubKey []byte          // public key of the one-time signing key, verified instead of recovery
+	powNonce        uint64          // proof-of-work stamp, ground by AttachPoW
+	hashAlg         TxHashAlgorithm // hash function of the tx hash, sha3-256 when not set
+	validFrom       int64           // unix seconds the tx is valid from, 0 for no lower bound
+	deadline        int64           // unix seconds the tx is valid until, 0 for no upper bound
+	forkMarker      []byte          // id of the intended fork, must match the node fork id when set
 
 	// Signature
 	alg  keystore.Algorithm
 	sign byteutils.Hash // Signature values
+
+	// node-local fields, not hashed and not serialized
+	replacesHash byteutils.Hash    // hash of the tx replaced by this speed-up tx
+	annotations  map[string]string // metadata attached by indexers
+	builder      *Address          // preferred block builder of the order flow
+	rawBytes     []byte            // wire bytes the tx was unmarshalled from, returned as is by Marshal
+	cachedHash   byteutils.Hash    // hash of the current fields, dropped whenever a hashed field is set
+	cachedDomain []byte            // hash domain cachedHash was computed under
+	frozen       bool              // set by signing, hashed fields can't be set until Thaw
 }
 
 // From return from address
@@ -100,11 +166,55 @@ func (tx *Transaction) From() *Address {
 	return tx.from
 }
 
+// TipRecipient return the receiver of the tip, nil means the block producer
+func (tx *Transaction) TipRecipient() *Address {
+	return tx.tipRecipient
+}
+
+// SetTipRecipient set the receiver of the tip, it is hashed so must be set before signing
+func (tx *Transaction) SetTipRecipient(addr *Address) error {
+	if tx.frozen {
+		return ErrTransactionFrozen
+	}
+	tx.tipRecipient = addr
+	tx.rawBytes = nil
+	tx.cachedHash = nil
+	return nil
+}
+
 // Timestamp return timestamp
 func (tx *Transaction) Timestamp() int64 {
 	return tx.timestamp
 }
 
+// ClockSkew return how far the tx timestamp is from local time, positive means in the future.
+func (tx *Transaction) ClockSkew() time.Duration {
+	return time.Unix(tx.timestamp, 0).Sub(time.Now())
+}
+
+// CheckClockSkew return ErrTransactionClockSkew if the tx timestamp is more than maxSkew away from local time.
+func (tx *Transaction) CheckClockSkew(maxSkew time.Duration) error {
+	skew := tx.ClockSkew()
+	if skew > maxSkew || skew < -maxSkew {
+		return ErrTransactionClockSkew
+	}
+	return nil
+}
+
+// VerifyTimestamp check the tx timestamp is at most window before now and TransactionFutureSkew after now,
+// so captured txs can't be replayed after the window.
+// timestamp is compared in unix seconds, so any int64 value from a peer is safe.
+func (tx *Transaction) VerifyTimestamp(now time.Time, window time.Duration) error {
+	nowSec := now.Unix()
+	if tx.timestamp < nowSec-int64(window/time.Second) {
+		return ErrExpiredTransaction
+	}
+	if tx.timestamp > nowSec+int64(TransactionFutureSkew/time.Second) {
+		return ErrFutureTransaction
+	}
+	return nil
+}
+
 // To return to address
 func (tx *Transaction) To() *Address {
 	return tx.to
@@ -135,21 +245,381 @@ func (tx *Transaction) Data() []byte {
 	return tx.data.Payload
 }
 
+// ReplacesHash return the hash of tx replaced by this tx, node-local only
+func (tx *Transaction) ReplacesHash() byteutils.Hash {
+	return tx.replacesHash
+}
+
+// SetReplacesHash tag the tx as a replacement of the given tx hash, node-local only
+func (tx *Transaction) SetReplacesHash(hash byteutils.Hash) {
+	tx.replacesHash = hash
+}
+
+// PreferredBuilder return the block builder the tx prefers to be routed to, node-local only
+func (tx *Transaction) PreferredBuilder() *Address {
+	return tx.builder
+}
+
+// SetPreferredBuilder set the block builder the tx prefers to be routed to, node-local only
+func (tx *Transaction) SetPreferredBuilder(addr *Address) {
+	tx.builder = addr
+}
+
+// SetAnnotation attach a node-local metadata to tx, it's never hashed or serialized
+func (tx *Transaction) SetAnnotation(key, value string) {
+	if tx.annotations == nil {
+		tx.annotations = make(map[string]string)
+	}
+	tx.annotations[key] = value
+}
+
+// GetAnnotation return the node-local metadata of given key
+func (tx *Transaction) GetAnnotation(key string) (string, bool) {
+	value, ok := tx.annotations[key]
+	return value, ok
+}
+
+// IsSpeedUpOf check if the tx is a speed-up replacement of original tx,
+// which has the same sender and nonce but a higher gasPrice.
+func (tx *Transaction) IsSpeedUpOf(original *Transaction) bool {
+	if original == nil {
+		return false
+	}
+	if !tx.from.Equals(original.from) || tx.nonce != original.nonce {
+		return false
+	}
+	return tx.gasPrice.Cmp(original.gasPrice) > 0
+}
+
+// NewCancelTransaction create a zero value self transfer with the same nonce and a higher gasPrice,
+// it replaces the original tx in pool so the pending nonce is used by nothing else.
+func NewCancelTransaction(original *Transaction) (*Transaction, error) {
+	if original == nil {
+		return nil, ErrNilArgument
+	}
+	percent, err := util.NewUint128FromInt(100 + CancelGasPriceBumpPercent)
+	if err != nil {
+		return nil, err
+	}
+	hundred, _ := util.NewUint128FromInt(100)
+	gasPrice, err := original.gasPrice.Mul(percent)
+	if err != nil {
+		return nil, err
+	}
+	gasPrice, err = gasPrice.Div(hundred)
+	if err != nil {
+		return nil, err
+	}
+	if gasPrice.Cmp(original.gasPrice) <= 0 {
+		gasPrice, err = original.gasPrice.Add(util.NewUint128FromUint(1))
+		if err != nil {
+			return nil, err
+		}
+	}
+	return NewTransaction(original.chainID, original.from, original.from, util.NewUint128(), original.nonce, TxPayloadBinaryType, nil, gasPrice, original.gasLimit)
+}
+
+// IsCancelOf check if the tx is a cancel tx of original, a zero value self transfer
+// without data, from the same sender with the same nonce and a higher gasPrice.
+func (tx *Transaction) IsCancelOf(original *Transaction) bool {
+	if !tx.IsSpeedUpOf(original) {
+		return false
+	}
+	return tx.to.Equals(tx.from) &&
+		tx.value.Cmp(util.NewUint128()) == 0 &&
+		tx.Type() == TxPayloadBinaryType &&
+		len(tx.Data()) == 0
+}
+
+// Fee return the max fee of the tx, paid when all the gas is used, gasPrice * gasLimit.
+func (tx *Transaction) Fee() (*util.Uint128, error) {
+	return tx.gasPrice.Mul(tx.gasLimit)
+}
+
+// RequiredBalance return the balance required by the tx, value + gasPrice * gasLimit.
+func (tx *Transaction) RequiredBalance() (*util.Uint128, error) {
+	fee, err := tx.Fee()
+	if err != nil {
+		return nil, err
+	}
+	return fee.Add(tx.value)
+}
+
+// PremiumOverMedian return gasPrice - medianGasPrice, negative when the tx pays below the median.
+func (tx *Transaction) PremiumOverMedian(medianGasPrice *util.Uint128) *big.Int {
+	premium := new(big.Int).SetBytes(tx.gasPrice.Bytes())
+	return premium.Sub(premium, new(big.Int).SetBytes(medianGasPrice.Bytes()))
+}
+
+// MarginalPrice return the gas price of tx discounted by the share of the remaining block space it takes:
+//
+//	remaining = blockGasLimit - blockGasUsed
+//	marginal  = gasPrice * (remaining - gasLimit) / remaining, rounded down
+//
+// it's close to gasPrice in an empty block and falls as the block fills, 0 when the tx doesn't fit.
+func (tx *Transaction) MarginalPrice(blockGasUsed, blockGasLimit uint64) *big.Int {
+	if blockGasUsed >= blockGasLimit {
+		return new(big.Int)
+	}
+	remaining := new(big.Int).SetUint64(blockGasLimit - blockGasUsed)
+	gasLimit := new(big.Int).SetBytes(tx.gasLimit.Bytes())
+	if gasLimit.Cmp(remaining) > 0 {
+		return new(big.Int)
+	}
+	marginal := new(big.Int).SetBytes(tx.gasPrice.Bytes())
+	marginal.Mul(marginal, new(big.Int).Sub(remaining, gasLimit))
+	return marginal.Div(marginal, remaining)
+}
+
+// SuspiciousGasRatio check if Fee() / value is over threshold, a high max fee for the value moved may be a draining attempt.
+// zero value calls are exempt, other zero value txs have an infinite ratio.
+func (tx *Transaction) SuspiciousGasRatio(threshold float64) bool {
+	zeroValue := tx.value.Cmp(util.NewUint128()) == 0
+	if zeroValue && tx.Type() == TxPayloadCallType {
+		return false
+	}
+	fee, err := tx.Fee()
+	if err != nil {
+		return true
+	}
+	if zeroValue {
+		return true
+	}
+	ratio := new(big.Float).SetInt(new(big.Int).SetBytes(fee.Bytes()))
+	ratio.Quo(ratio, new(big.Float).SetInt(new(big.Int).SetBytes(tx.value.Bytes())))
+	return ratio.Cmp(big.NewFloat(threshold)) > 0
+}
+
+// ValidForBaseFee check the tx pays at least baseFee per gas, txs only have a fixed gasPrice.
+func (tx *Transaction) ValidForBaseFee(baseFee *util.Uint128) error {
+	if baseFee == nil {
+		return ErrNilArgument
+	}
+	if tx.gasPrice.Cmp(baseFee) < 0 {
+		return ErrFeeTooLowForBaseFee
+	}
+	return nil
+}
+
+// EffectiveTip return what the block producer earns per gas above baseFee, gasPrice - baseFee,
+// zero if gasPrice is below baseFee.
+func (tx *Transaction) EffectiveTip(baseFee *util.Uint128) *util.Uint128 {
+	if baseFee == nil {
+		return tx.gasPrice
+	}
+	if tx.gasPrice.Cmp(baseFee) <= 0 {
+		return util.NewUint128()
+	}
+	tip, err := tx.gasPrice.Sub(baseFee)
+	if err != nil {
+		return util.NewUint128()
+	}
+	return tip
+}
+
+// EffectiveCost return the max the sender pays under baseFee with dynamic fee semantics,
+// value + (baseFee + EffectiveTip(baseFee)) * gasLimit. gasPrice is both the fee cap and the tip cap
+// of a tx, so an included tx costs value + gasPrice * gasLimit, while a tx paying below baseFee
+// can't be included and costs nothing. unlike RequiredBalance it's computed in big.Int and can't overflow,
+// nil baseFee is zero.
+func (tx *Transaction) EffectiveCost(baseFee *util.Uint128) *big.Int {
+	if baseFee == nil {
+		baseFee = util.NewUint128()
+	}
+	if tx.ValidForBaseFee(baseFee) != nil {
+		return new(big.Int)
+	}
+	price := new(big.Int).SetBytes(tx.EffectiveTip(baseFee).Bytes())
+	price.Add(price, new(big.Int).SetBytes(baseFee.Bytes()))
+	cost := price.Mul(price, new(big.Int).SetBytes(tx.gasLimit.Bytes()))
+	return cost.Add(cost, new(big.Int).SetBytes(tx.value.Bytes()))
+}
+
+// GasRefund return the fee of unused gas, (gasLimit - gasUsed) * gasPrice, zero if gasUsed is over gasLimit.
+func (tx *Transaction) GasRefund(gasUsed *util.Uint128) *util.Uint128 {
+	if gasUsed == nil || tx.gasLimit.Cmp(gasUsed) <= 0 {
+		return util.NewUint128()
+	}
+	unused, err := tx.gasLimit.Sub(gasUsed)
+	if err != nil {
+		return util.NewUint128()
+	}
+	// unused gas < gasLimit, so the refund can not overflow a valid Fee.
+	refund, err := unused.Mul(tx.gasPrice)
+	if err != nil {
+		return util.NewUint128()
+	}
+	return refund
+}
+
+// RespectsReserve check the balance left after the tx is not less than reserve.
+func (tx *Transaction) RespectsReserve(balance, reserve *util.Uint128) bool {
+	if balance == nil || reserve == nil {
+		return false
+	}
+	required, err := tx.RequiredBalance()
+	if err != nil || balance.Cmp(required) < 0 {
+		return false
+	}
+	left, err := balance.Sub(required)
+	if err != nil {
+		return false
+	}
+	return left.Cmp(reserve) >= 0
+}
+
+// FeeBumpOver return how much more the tx pays than the original tx it replaces,
+// the fee of a tx is gasPrice * gasLimit.
+func (tx *Transaction) FeeBumpOver(original *Transaction) (*util.Uint128, error) {
+	if original == nil {
+		return nil, ErrNilArgument
+	}
+	if !tx.from.Equals(original.from) || tx.nonce != original.nonce {
+		return nil, ErrNotReplacementTransaction
+	}
+	fee, err := tx.Fee()
+	if err != nil {
+		return nil, err
+	}
+	originalFee, err := original.Fee()
+	if err != nil {
+		return nil, err
+	}
+	if fee.Cmp(originalFee) < 0 {
+		return nil, ErrInsufficientFeeBump
+	}
+	return fee.Sub(originalFee)
+}
+
+// MeetsMinBump check if the tx bumps the fee of original tx by at least minPercent percent.
+func (tx *Transaction) MeetsMinBump(original *Transaction, minPercent int) bool {
+	if minPercent < 0 {
+		return false
+	}
+	bump, err := tx.FeeBumpOver(original)
+	if err != nil {
+		return false
+	}
+	originalFee, err := original.Fee()
+	if err != nil {
+		return false
+	}
+	percent, err := util.NewUint128FromInt(int64(minPercent))
+	if err != nil {
+		return false
+	}
+	hundred, _ := util.NewUint128FromInt(100)
+
+	// bump * 100 >= originalFee * minPercent
+	scaledBump, err := bump.Mul(hundred)
+	if err != nil {
+		return false
+	}
+	minBump, err := originalFee.Mul(percent)
+	if err != nil {
+		return false
+	}
+	return scaledBump.Cmp(minBump) >= 0
+}
+
+// MinReplacementFee return the lowest fee a replacement of tx must pay to pass MeetsMinBump with bumpPercent,
+// ceil(fee * (100 + bumpPercent) / 100). a negative bumpPercent is taken as 0.
+func (tx *Transaction) MinReplacementFee(bumpPercent int) *big.Int {
+	if bumpPercent < 0 {
+		bumpPercent = 0
+	}
+	fee := new(big.Int).Mul(new(big.Int).SetBytes(tx.gasPrice.Bytes()), new(big.Int).SetBytes(tx.gasLimit.Bytes()))
+	fee.Mul(fee, big.NewInt(int64(100+bumpPercent)))
+	fee.Add(fee, big.NewInt(99))
+	return fee.Div(fee, big.NewInt(100))
+}
+
+// BuildReplacement create an unsigned copy of tx with the lowest gasPrice paying MinReplacementFee,
+// and higher than the gasPrice of tx, so the copy is also a speed-up of tx.
+func (tx *Transaction) BuildReplacement(bumpPercent int) (*Transaction, error) {
+	gasLimit := new(big.Int).SetBytes(tx.gasLimit.Bytes())
+	if gasLimit.Sign() == 0 {
+		return nil, ErrZeroGasLimit
+	}
+	// ceil(minFee / gasLimit)
+	price := new(big.Int).Add(tx.MinReplacementFee(bumpPercent), gasLimit)
+	price.Sub(price, big.NewInt(1))
+	price.Div(price, gasLimit)
+	if original := new(big.Int).SetBytes(tx.gasPrice.Bytes()); price.Cmp(original) <= 0 {
+		price.Add(original, big.NewInt(1))
+	}
+	gasPrice, err := util.NewUint128FromBigInt(price)
+	if err != nil {
+		return nil, err
+	}
+	if gasPrice.Cmp(TransactionMaxGasPrice) > 0 {
+		return nil, ErrInvalidGasPrice
+	}
+
+	replacement := tx.Clone()
+	replacement.gasPrice = gasPrice
+	replacement.hash, replacement.alg, replacement.sign = nil, 0, nil
+	replacement.frozen = false
+	replacement.SetReplacesHash(tx.hash)
+	return replacement, nil
+}
+
+// VerifyInclusion check proof proves txHash is the index-th leaf of the tree of Transactions.MerkleRoot.
+// proof is made by Transactions.MerkleProof, it has one sibling per level from the leaves up, nil for the levels
+// the node is the odd one promoted without hashing, so the promotion rule of MerkleRoot is replayed exactly.
+func VerifyInclusion(txHash byteutils.Hash, proof []byteutils.Hash, index int, blockTxRoot byteutils.Hash) bool {
+	return verifyMerkleProof(txHash, proof, index, blockTxRoot)
+}
+
+// CheckRecipient check tx's recipient against the policy before the tx is signed and sent.
+// a nil policy allows every recipient.
+func (tx *Transaction) CheckRecipient(policy AddressPolicy) error {
+	if policy == nil {
+		return nil
+	}
+	return policy.CheckRecipient(tx.to)
+}
+
+// ValidateCallTarget check the to address of a call tx has contract code, the call fails otherwise.
+// txs of other types and a nil oracle pass.
+func (tx *Transaction) ValidateCallTarget(oracle CodeOracle) error {
+	if oracle == nil || tx.Type() != TxPayloadCallType {
+		return nil
+	}
+	hasCode, err := oracle.HasCode(tx.to)
+	if err != nil {
+		return err
+	}
+	if !hasCode {
+		return ErrCallToNonContract
+	}
+	return nil
+}
+
 // ToProto converts domain Tx to proto Tx
 func (tx *Transaction) ToProto() (proto.Message, error) {
+	msg := new(corepb.Transaction)
+	if err := tx.fillProto(msg); err != nil {
+		return nil, err
+	}
+	return msg, nil
+}
+
+// fillProto overwrite all the fields of msg with tx, so msg can be reused.
+func (tx *Transaction) fillProto(msg *corepb.Transaction) error {
 	value, err := tx.value.ToFixedSizeByteSlice()
 	if err != nil {
-		return nil, err
+		return err
 	}
 	gasPrice, err := tx.gasPrice.ToFixedSizeByteSlice()
 	if err != nil {
-		return nil, err
+		return err
 	}
 	gasLimit, err := tx.gasLimit.ToFixedSizeByteSlice()
 	if err != nil {
-		return nil, err
+		return err
 	}
-	return &corepb.Transaction{
+	*msg = corepb.Transaction{
 		Hash:      tx.hash,
 		From:      tx.from.address,
 		To:        tx.to.address,
@@ -162,13 +632,26 @@ func (tx *Transaction) ToProto() (proto.Message, error) {
 		GasLimit:  gasLimit,
 		Alg:       uint32(tx.alg),
 		Sign:      tx.sign,
-	}, nil
+		Salt:      tx.salt,
+	}
+	if tx.tipRecipient != nil {
+		msg.TipRecipient = tx.tipRecipient.address
+	}
+	msg.EphemeralPubKey = tx.ephemeralPubKey
+	msg.PowNonce = tx.powNonce
+	msg.HashAlg = uint32(tx.hashAlg)
+	msg.ValidFrom = tx.validFrom
+	msg.Deadline = tx.deadline
+	msg.ForkMarker = tx.forkMarker
+	return nil
 }
 
 // FromProto converts proto Tx into domain Tx
 func (tx *Transaction) FromProto(msg proto.Message) error {
 	if msg, ok := msg.(*corepb.Transaction); ok {
 		if msg != nil {
+			// the hash on the wire is not trusted, it's checked against the fields by verification.
+			tx.cachedHash = nil
 			tx.hash = msg.Hash
 			from, err := AddressParseFromBytes(msg.From)
 			if err != nil {
@@ -189,6 +672,34 @@ func (tx *Transaction) FromProto(msg proto.Message) error {
 			tx.value = value
 
 			tx.nonce = msg.Nonce
+			if len(msg.Salt) != 0 && len(msg.Salt) != ReplaySaltLength {
+				return ErrInvalidReplaySalt
+			}
+			tx.salt = msg.Salt
+
+			tx.tipRecipient = nil
+			if len(msg.TipRecipient) > 0 {
+				tipRecipient, err := AddressParseFromBytes(msg.TipRecipient)
+				if err != nil {
+					return err
+				}
+				tx.tipRecipient = tipRecipient
+			}
+
+			if len(msg.EphemeralPubKey) > 0 {
+				if err := checkEphemeralPubKey(msg.EphemeralPubKey); err != nil {
+					return err
+				}
+			}
+			tx.ephemeralPubKey = msg.EphemeralPubKey
+			tx.powNonce = msg.PowNonce
+			if err := checkHashAlgorithm(TxHashAlgorithm(msg.HashAlg)); err != nil {
+				return err
+			}
+			tx.hashAlg = TxHashAlgorithm(msg.HashAlg)
+			tx.validFrom = msg.ValidFrom
+			tx.deadline = msg.Deadline
+			tx.forkMarker = msg.ForkMarker
 			tx.timestamp = msg.Timestamp
 			tx.chainID = msg.ChainId
 
@@ -253,8 +764,459 @@ func (tx *Transaction) String() string {
 	)
 }
 
-// Transactions is an alias of Transaction array.
-type Transactions []*Transaction
+// Equals check if two txs have the same content, node-local fields are ignored.
+func (tx *Transaction) Equals(other *Transaction) bool {
+	if tx == other {
+		return true
+	}
+	if tx == nil || other == nil {
+		return false
+	}
+	// short cut, txs with different computed hashes are not equal.
+	// full comparison is still needed when hashes match.
+	if len(tx.hash) > 0 && len(other.hash) > 0 && !tx.hash.Equals(other.hash) {
+		return false
+	}
+	return tx.hash.Equals(other.hash) &&
+		tx.from.Equals(other.from) &&
+		tx.to.Equals(other.to) &&
+		tx.value.Cmp(other.value) == 0 &&
+		tx.nonce == other.nonce &&
+		tx.timestamp == other.timestamp &&
+		tx.data.GetType() == other.data.GetType() &&
+		byteutils.Equal(tx.data.GetPayload(), other.data.GetPayload()) &&
+		tx.chainID == other.chainID &&
+		tx.gasPrice.Cmp(other.gasPrice) == 0 &&
+		tx.gasLimit.Cmp(other.gasLimit) == 0 &&
+		byteutils.Equal(tx.salt, other.salt) &&
+		tx.tipRecipient.Equals(other.tipRecipient) &&
+		byteutils.Equal(tx.ephemeralPubKey, other.ephemeralPubKey) &&
+		tx.powNonce == other.powNonce &&
+		tx.hashAlg == other.hashAlg &&
+		tx.validFrom == other.validFrom &&
+		tx.deadline == other.deadline &&
+		byteutils.Equal(tx.forkMarker, other.forkMarker) &&
+		tx.alg == other.alg &&
+		tx.sign.Equals(other.sign)
+}
+
+// IntentID return the hash of what the tx does, to, value, data and chainID,
+// it does not change when the tx is re-signed with a new timestamp or gas.
+func (tx *Transaction) IntentID() (byteutils.Hash, error) {
+	value, err := tx.value.ToFixedSizeByteSlice()
+	if err != nil {
+		return nil, err
+	}
+	data, err := proto.Marshal(tx.data)
+	if err != nil {
+		return nil, err
+	}
+	return hash.Sha3256(tx.to.address, value, data, byteutils.FromUint32(tx.chainID)), nil
+}
+
+// IdempotencyKey return "hex(from):nonce:hex(IntentID)", retries of the same intent share the key.
+func (tx *Transaction) IdempotencyKey() string {
+	intent, err := tx.IntentID()
+	if err != nil {
+		return ""
+	}
+	return fmt.Sprintf("%s:%d:%s", tx.from.address.Hex(), tx.nonce, intent.Hex())
+}
+
+// TrieKey return the key of the tx in the txs trie of world state.
+// the key is the raw tx hash, the trie splits it into a path of 4-bit nibbles,
+// high nibble first, e.g. {0xa1, 0xf2} -> {0xa, 0x1, 0xf, 0x2}.
+func (tx *Transaction) TrieKey() []byte {
+	key := make([]byte, len(tx.hash))
+	copy(key, tx.hash)
+	return key
+}
+
+// multihash prefix of sha2-256 digests: function code, digest length
+var cidMultihashPrefix = []byte{0x12, 0x20}
+
+// StorageCID return the content identifier of the tx for content-addressed storage,
+// base58 of the sha2-256 multihash of the serialized tx, "" if the tx can't be encoded.
+// node-local fields are not serialized, so they don't change the CID.
+func (tx *Transaction) StorageCID() string {
+	pbTx, err := tx.ToProto()
+	if err != nil {
+		return ""
+	}
+	data, err := proto.Marshal(pbTx)
+	if err != nil {
+		return ""
+	}
+	return base58.Encode(append(append([]byte{}, cidMultihashPrefix...), hash.Sha256(data)...))
+}
+
+// ShortID return the first 8 bytes of tx hash, used to announce txs in gossip.
+// the probability of any collision among n txs is about n^2 / 2^65, ~3*10^-8 for a million txs,
+// so peers must still check the full hash after fetching the tx body.
+func (tx *Transaction) ShortID() [8]byte {
+	var id [8]byte
+	copy(id[:], tx.hash)
+	return id
+}
+
+// SigningSummary return a deterministic text description of the tx for the signer to approve.
+// amounts are in wei, fee is the max fee gasPrice * gasLimit, signature is not included.
+func (tx *Transaction) SigningSummary() string {
+	fee := "overflow"
+	if maxFee, err := tx.Fee(); err == nil {
+		fee = maxFee.String()
+	}
+	return fmt.Sprintf("From: %s\nTo: %s\nAmount: %s\nFee: %s\nNonce: %d\nChainID: %d\nType: %s\nData size: %d",
+		tx.from.String(),
+		tx.to.String(),
+		tx.value.String(),
+		fee,
+		tx.nonce,
+		tx.chainID,
+		tx.Type(),
+		len(tx.Data()),
+	)
+}
+
+// Transactions is an alias of Transaction array.
+type Transactions []*Transaction
+
+// FitWithinGas split txs in order, the included txs sum of gasLimit is not greater than blockGasLimit,
+// the first tx over the limit and all the txs after it are excluded.
+func (txs Transactions) FitWithinGas(blockGasLimit *util.Uint128) (included, excluded Transactions) {
+	used := util.NewUint128()
+	for i, tx := range txs {
+		next, err := used.Add(tx.gasLimit)
+		if err != nil || next.Cmp(blockGasLimit) > 0 {
+			excluded = append(excluded, txs[i:]...)
+			return included, excluded
+		}
+		used = next
+		included = append(included, tx)
+	}
+	return included, excluded
+}
+
+// ShortIDs return ShortID of each tx in order.
+func (txs Transactions) ShortIDs() [][8]byte {
+	ids := make([][8]byte, len(txs))
+	for i, tx := range txs {
+		ids[i] = tx.ShortID()
+	}
+	return ids
+}
+
+// AffordableFor walk the sender's txs in nonce order and subtract each value + gasPrice * gasLimit from balance,
+// the first tx the balance can not cover and all the sender's txs after it are unaffordable.
+func (txs Transactions) AffordableFor(from *Address, balance *util.Uint128) (affordable, unaffordable Transactions) {
+	senderTxs := txs.senderTxsByNonce(from)
+	left := balance
+	for i, tx := range senderTxs {
+		cost, err := tx.RequiredBalance()
+		if err != nil || left.Cmp(cost) < 0 {
+			unaffordable = append(unaffordable, senderTxs[i:]...)
+			return affordable, unaffordable
+		}
+		left, _ = left.Sub(cost)
+		affordable = append(affordable, tx)
+	}
+	return affordable, unaffordable
+}
+
+// WithinSpendingCap walk the sender's txs in nonce order and sum up each value,
+// the first tx taking the sum over spendingCap and all the sender's txs after it are over cap.
+func (txs Transactions) WithinSpendingCap(from *Address, spendingCap *util.Uint128) (allowed, overCap Transactions) {
+	senderTxs := txs.senderTxsByNonce(from)
+	spent := util.NewUint128()
+	for i, tx := range senderTxs {
+		next, err := spent.Add(tx.value)
+		if err != nil || next.Cmp(spendingCap) > 0 {
+			overCap = append(overCap, senderTxs[i:]...)
+			return allowed, overCap
+		}
+		spent = next
+		allowed = append(allowed, tx)
+	}
+	return allowed, overCap
+}
+
+// EnforcePerSenderBlockCap keep the maxPerSender lowest nonce txs of each sender and reject the others.
+// txs keep the positions of their sender in txs, filled by the sender's txs in nonce order,
+// so the txs of each sender are nonce ascending in both kept and rejected.
+func (txs Transactions) EnforcePerSenderBlockCap(maxPerSender int) (kept, rejected Transactions) {
+	taken := make(map[string]int)
+	for _, tx := range txs.inSenderNonceOrder() {
+		key := tx.from.String()
+		if taken[key] < maxPerSender {
+			kept = append(kept, tx)
+		} else {
+			rejected = append(rejected, tx)
+		}
+		taken[key]++
+	}
+	return kept, rejected
+}
+
+// inSenderNonceOrder return txs with the positions of each sender filled by the sender's txs in nonce order.
+func (txs Transactions) inSenderNonceOrder() Transactions {
+	bySender := make(map[string]Transactions)
+	for _, tx := range txs {
+		key := tx.from.String()
+		bySender[key] = append(bySender[key], tx)
+	}
+	for _, senderTxs := range bySender {
+		sort.SliceStable(senderTxs, func(i, j int) bool {
+			return senderTxs[i].nonce < senderTxs[j].nonce
+		})
+	}
+
+	ordered := make(Transactions, len(txs))
+	taken := make(map[string]int)
+	for i, tx := range txs {
+		key := tx.from.String()
+		ordered[i] = bySender[key][taken[key]]
+		taken[key]++
+	}
+	return ordered
+}
+
+func (txs Transactions) senderTxsByNonce(from *Address) Transactions {
+	var senderTxs Transactions
+	for _, tx := range txs {
+		if tx != nil && tx.from.Equals(from) {
+			senderTxs = append(senderTxs, tx)
+		}
+	}
+	sort.SliceStable(senderTxs, func(i, j int) bool {
+		return senderTxs[i].nonce < senderTxs[j].nonce
+	})
+	return senderTxs
+}
+
+// RemoveStale drop the txs whose nonce is already used by the sender account, the order of the rest is kept.
+// accountNonces is keyed by sender address string, the tx is stale if tx.nonce < accountNonce + 1
+// the same as in VerifyExecution. txs of unknown senders are kept.
+func (txs Transactions) RemoveStale(accountNonces map[string]uint64) Transactions {
+	var fresh Transactions
+	for _, tx := range txs {
+		if accountNonce, ok := accountNonces[tx.from.String()]; ok && tx.nonce <= accountNonce {
+			continue
+		}
+		fresh = append(fresh, tx)
+	}
+	return fresh
+}
+
+// WouldRegressNonce return the hashes of the txs whose nonce is not greater than the account nonce of the sender,
+// importing them would move the account nonce backward. accountNonces is keyed by sender address string,
+// txs of unknown senders are not reported.
+func (txs Transactions) WouldRegressNonce(accountNonces map[string]uint64) []byteutils.Hash {
+	var hashes []byteutils.Hash
+	for _, tx := range txs {
+		if accountNonce, ok := accountNonces[tx.from.String()]; ok && tx.nonce <= accountNonce {
+			hashes = append(hashes, tx.hash)
+		}
+	}
+	return hashes
+}
+
+// FilterNonceHorizon drop the txs whose nonce is beyond horizon from the sender account nonce, the order of the rest is kept.
+// accountNonces is keyed by sender address string as in RemoveStale, txs of unknown senders are kept.
+func (txs Transactions) FilterNonceHorizon(accountNonces map[string]uint64, horizon uint64) Transactions {
+	var within Transactions
+	for _, tx := range txs {
+		if accountNonce, ok := accountNonces[tx.from.String()]; ok && !tx.WithinNonceHorizon(accountNonce, horizon) {
+			continue
+		}
+		within = append(within, tx)
+	}
+	return within
+}
+
+// RouteByChainID group txs by chain id in order, txs of chains not in allowed are dropped.
+func (txs Transactions) RouteByChainID(allowed []uint32) (routes map[uint32]Transactions, dropped Transactions) {
+	routes = make(map[uint32]Transactions)
+	for _, tx := range txs {
+		if !tx.ChainIDAllowed(allowed) {
+			dropped = append(dropped, tx)
+			continue
+		}
+		routes[tx.chainID] = append(routes[tx.chainID], tx)
+	}
+	return routes, dropped
+}
+
+// ByPreferredBuilder group txs by preferred builder address string in order, txs without one are keyed by "".
+func (txs Transactions) ByPreferredBuilder() map[string]Transactions {
+	groups := make(map[string]Transactions)
+	for _, tx := range txs {
+		key := ""
+		if tx.builder != nil {
+			key = tx.builder.String()
+		}
+		groups[key] = append(groups[key], tx)
+	}
+	return groups
+}
+
+// TotalIntrinsicGas return the sum of IntrinsicGas of txs, error if any tx fails or the sum overflows.
+func (txs Transactions) TotalIntrinsicGas() (*util.Uint128, error) {
+	total := util.NewUint128()
+	for _, tx := range txs {
+		gas, err := tx.IntrinsicGas()
+		if err != nil {
+			return nil, err
+		}
+		total, err = total.Add(gas)
+		if err != nil {
+			return nil, ErrGasCntOverflow
+		}
+	}
+	return total, nil
+}
+
+// TotalBurned return the sum of value of the burn txs.
+func (txs Transactions) TotalBurned() (*util.Uint128, error) {
+	total := util.NewUint128()
+	for _, tx := range txs {
+		if !tx.IsBurn() {
+			continue
+		}
+		var err error
+		total, err = total.Add(tx.value)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return total, nil
+}
+
+// StripSignatures return copies of txs with sign cleared and the signs in the same order,
+// hash and alg are kept in the copies.
+func (txs Transactions) StripSignatures() (bodies Transactions, sigs [][]byte) {
+	bodies = make(Transactions, len(txs))
+	sigs = make([][]byte, len(txs))
+	for i, tx := range txs {
+		body := tx.Clone()
+		sigs[i] = body.sign
+		body.sign = nil
+		bodies[i] = body
+	}
+	return bodies, sigs
+}
+
+// ReattachSignatures return copies of bodies with the signs attached in order, inverse of StripSignatures.
+func ReattachSignatures(bodies Transactions, sigs [][]byte) (Transactions, error) {
+	if len(bodies) != len(sigs) {
+		return nil, ErrInvalidArgument
+	}
+	txs := make(Transactions, len(bodies))
+	for i, body := range bodies {
+		tx := body.Clone()
+		tx.sign = sigs[i]
+		txs[i] = tx
+	}
+	return txs, nil
+}
+
+// OrderingSeed return the hash over the sorted tx hashes, it does not depend on the order of txs.
+func (txs Transactions) OrderingSeed() byteutils.Hash {
+	hashes := make([][]byte, len(txs))
+	for i, tx := range txs {
+		hashes[i] = tx.hash
+	}
+	sort.Slice(hashes, func(i, j int) bool {
+		return bytes.Compare(hashes[i], hashes[j]) < 0
+	})
+	return hash.Sha3256(hashes...)
+}
+
+// MerkleRoot return the binary merkle root over the tx hashes in order, an odd node is promoted to the next level.
+// leaves and inner nodes are hashed with different prefixes, so a root can't be forged from inner nodes.
+func (txs Transactions) MerkleRoot() byteutils.Hash {
+	hashes := make([][]byte, len(txs))
+	for i, tx := range txs {
+		hashes[i] = tx.hash
+	}
+	return merkleRoot(hashes)
+}
+
+// MerkleProof return the proof of the index-th tx against MerkleRoot, checked by VerifyInclusion.
+func (txs Transactions) MerkleProof(index int) ([]byteutils.Hash, error) {
+	hashes := make([][]byte, len(txs))
+	for i, tx := range txs {
+		hashes[i] = tx.hash
+	}
+	return merkleProof(hashes, index)
+}
+
+// CommitmentEquals check if txs and other commit to the same block, i.e. the same txs in the same order,
+// block hash covers the tx hashes in order, so reordered txs give a different block.
+func (txs Transactions) CommitmentEquals(other Transactions) bool {
+	return txs.MerkleRoot().Equals(other.MerkleRoot())
+}
+
+// SetEquals check if txs and other have the same tx hashes regardless of order,
+// each hash must appear the same number of times in both.
+func (txs Transactions) SetEquals(other Transactions) bool {
+	if len(txs) != len(other) {
+		return false
+	}
+	counts := make(map[byteutils.HexHash]int, len(txs))
+	for _, tx := range txs {
+		counts[tx.hash.Hex()]++
+	}
+	for _, tx := range other {
+		key := tx.hash.Hex()
+		if counts[key] == 0 {
+			return false
+		}
+		counts[key]--
+	}
+	return true
+}
+
+// ShuffleDeterministic permute txs in place by seed, txs are sorted by hash first,
+// so the same set of txs and seed always give the same order.
+func (txs Transactions) ShuffleDeterministic(seed byteutils.Hash) {
+	sort.Slice(txs, func(i, j int) bool {
+		return bytes.Compare(txs[i].hash, txs[j].hash) < 0
+	})
+	// Fisher-Yates, the swap index of step i is drawn from sha3(seed, i).
+	for i := len(txs) - 1; i > 0; i-- {
+		r := binary.BigEndian.Uint64(hash.Sha3256(seed, byteutils.FromUint64(uint64(i))))
+		j := int(r % uint64(i+1))
+		txs[i], txs[j] = txs[j], txs[i]
+	}
+}
+
+// SortByEffectiveTip sort txs in place by EffectiveTip under baseFee from high to low,
+// txs with equal tips keep their order.
+func (txs Transactions) SortByEffectiveTip(baseFee *util.Uint128) {
+	tips := make(map[*Transaction]*util.Uint128, len(txs))
+	for _, tx := range txs {
+		tips[tx] = tx.EffectiveTip(baseFee)
+	}
+	sort.SliceStable(txs, func(i, j int) bool {
+		return tips[txs[i]].Cmp(tips[txs[j]]) > 0
+	})
+}
+
+// NextNonceFor return the nonce of the next tx from the sender after the txs in batch are executed.
+// currentNonce is the account nonce, txs nonce must follow it one by one, counting stops at the first gap.
+func (txs Transactions) NextNonceFor(from *Address, currentNonce uint64) uint64 {
+	nonces := make(map[uint64]bool)
+	for _, tx := range txs {
+		if tx != nil && tx.from.Equals(from) {
+			nonces[tx.nonce] = true
+		}
+	}
+	next := currentNonce + 1
+	for nonces[next] {
+		next++
+	}
+	return next
+}
 
 // NewTransaction create #Transaction instance.
 func NewTransaction(chainID uint32, from, to *Address, value *util.Uint128, nonce uint64, payloadType string, payload []byte, gasPrice *util.Uint128, gasLimit *util.Uint128) (*Transaction, error) {
@@ -269,6 +1231,14 @@ func NewTransaction(chainID uint32, from, to *Address, value *util.Uint128, nonc
 		return nil, ErrInvalidArgument
 	}
 
+	// addresses must pass the same checks as parsed ones, self-sends are valid as deploys and cancels use them.
+	if _, err := AddressParseFromBytes(from.address); err != nil {
+		return nil, err
+	}
+	if _, err := AddressParseFromBytes(to.address); err != nil {
+		return nil, err
+	}
+
 	if len(payload) > MaxDataPayLoadLength {
 		return nil, ErrTxDataPayLoadOutOfMaxLength
 	}
@@ -287,6 +1257,38 @@ func NewTransaction(chainID uint32, from, to *Address, value *util.Uint128, nonc
 	return tx, nil
 }
 
+// Clone return a copy of tx, including node-local fields except the raw wire bytes.
+func (tx *Transaction) Clone() *Transaction {
+	ntx := *tx
+	// clones are usually modified, so they are marshalled and hashed again.
+	ntx.rawBytes = nil
+	ntx.cachedHash = nil
+	if tx.data != nil {
+		ntx.data = &corepb.Data{Type: tx.data.Type, Payload: tx.data.Payload}
+	}
+	if tx.annotations != nil {
+		ntx.annotations = make(map[string]string, len(tx.annotations))
+		for k, v := range tx.annotations {
+			ntx.annotations[k] = v
+		}
+	}
+	return &ntx
+}
+
+// WithIncrementedNonce return an unsigned clone of the tx with nonce + 1, ready to be signed again.
+func (tx *Transaction) WithIncrementedNonce() (*Transaction, error) {
+	if tx.nonce == math.MaxUint64 {
+		return nil, ErrNonceOverflow
+	}
+	ntx := tx.Clone()
+	ntx.nonce = tx.nonce + 1
+	ntx.hash = nil
+	ntx.alg = 0
+	ntx.sign = nil
+	ntx.frozen = false
+	return ntx, nil
+}
+
 // Hash return the hash of transaction.
 func (tx *Transaction) Hash() byteutils.Hash {
 	return tx.hash
@@ -302,6 +1304,89 @@ func (tx *Transaction) GasLimit() *util.Uint128 {
 	return tx.gasLimit
 }
 
+// ChainIDAllowed check if the tx chain id is in allowed, used by relays serving several chains.
+func (tx *Transaction) ChainIDAllowed(allowed []uint32) bool {
+	for _, id := range allowed {
+		if tx.chainID == id {
+			return true
+		}
+	}
+	return false
+}
+
+// WithinNonceHorizon check tx.nonce <= accountNonce + horizon, pools use it to bound the queued txs of an account.
+func (tx *Transaction) WithinNonceHorizon(accountNonce uint64, horizon uint64) bool {
+	return tx.nonce <= accountNonce || tx.nonce-accountNonce <= horizon
+}
+
+// SequencePosition return how many txs of the sender execute before tx, 0 when it's the next to execute,
+// nonce accountNonce + 1, and negative when its nonce is already used.
+func (tx *Transaction) SequencePosition(accountNonce uint64) int {
+	return int(int64(tx.nonce - accountNonce - 1))
+}
+
+// IsSimpleTransfer check if the tx is a plain binary transfer without data to an account address,
+// such tx can be executed without the VM.
+func (tx *Transaction) IsSimpleTransfer() bool {
+	return tx.Type() == TxPayloadBinaryType && len(tx.data.Payload) == 0 && tx.to.Type() == AccountAddress
+}
+
+// IsBurn check if the tx sends value to the zero address, contract deploys are not burns.
+func (tx *Transaction) IsBurn() bool {
+	return tx.to.IsZero() && tx.value.Cmp(util.NewUint128()) > 0 && tx.Type() != TxPayloadDeployType
+}
+
+// IntrinsicGas return the gas charged before the payload is executed, tx base gas + payload base gas.
+func (tx *Transaction) IntrinsicGas() (*util.Uint128, error) {
+	baseGas, err := tx.GasCountOfTxBase()
+	if err != nil {
+		return nil, err
+	}
+	payload, err := tx.LoadPayload()
+	if err != nil {
+		return nil, err
+	}
+	return baseGas.Add(payload.BaseGasCount())
+}
+
+// VerifyIntrinsicGas check gasLimit covers the intrinsic gas, otherwise the tx can never be executed.
+func (tx *Transaction) VerifyIntrinsicGas() error {
+	gas, err := tx.IntrinsicGas()
+	if err != nil {
+		return err
+	}
+	if tx.gasLimit.Cmp(gas) < 0 {
+		return ErrGasLimitBelowIntrinsic
+	}
+	return nil
+}
+
+// VerifyAndEstimate verify the tx and return the gas it would consume, the estimator is only called for valid txs.
+// the gas of simple transfers is the intrinsic gas, they don't need an estimator.
+func (tx *Transaction) VerifyAndEstimate(chainID uint32, e GasEstimator) (uint64, error) {
+	if err := tx.VerifyIntegrity(chainID); err != nil {
+		return 0, err
+	}
+	if err := tx.VerifyIntrinsicGas(); err != nil {
+		return 0, err
+	}
+
+	var gas *util.Uint128
+	var err error
+	switch {
+	case tx.IsSimpleTransfer():
+		gas, err = tx.IntrinsicGas()
+	case e == nil:
+		return 0, ErrNilArgument
+	default:
+		gas, err = e.EstimateGas(tx)
+	}
+	if err != nil {
+		return 0, err
+	}
+	return gas.Uint64(), nil
+}
+
 // GasCountOfTxBase calculate the actual amount for a tx with data
 func (tx *Transaction) GasCountOfTxBase() (*util.Uint128, error) {
 	txGas := MinGasCountPerTransaction
@@ -323,6 +1408,23 @@ func (tx *Transaction) GasCountOfTxBase() (*util.Uint128, error) {
 	return txGas, nil
 }
 
+// EstimateConfirmationBlocks estimate how many blocks the tx will wait before inclusion.
+// feeHistogram maps a gasPrice (decimal string) to the count of pending txs with that gasPrice,
+// txs with a higher gasPrice are packed first, invalid gasPrice keys are ignored.
+func (tx *Transaction) EstimateConfirmationBlocks(feeHistogram map[string]int) int {
+	ahead := 0
+	for price, count := range feeHistogram {
+		gasPrice, err := util.NewUint128FromString(price)
+		if err != nil || count <= 0 {
+			continue
+		}
+		if gasPrice.Cmp(tx.gasPrice) > 0 {
+			ahead += count
+		}
+	}
+	return ahead/TransactionsPerBlockEstimate + 1
+}
+
 // DataLen return the length of payload
 func (tx *Transaction) DataLen() int {
 	return len(tx.data.Payload)
@@ -348,6 +1450,13 @@ func (tx *Transaction) LoadPayload() (TxPayload, error) {
 	return payload, err
 }
 
+// ValidatePayload check tx data can be decoded as the payload of its type,
+// binary payload accepts any data.
+func (tx *Transaction) ValidatePayload() error {
+	_, err := tx.LoadPayload()
+	return err
+}
+
 func submitTx(tx *Transaction, block *Block, ws WorldState, gas *util.Uint128, exeErr error, exeErrTy string) (bool, error) {
 	if exeErr != nil {
 		logging.VLog().WithFields(logrus.Fields{
@@ -509,7 +1618,7 @@ func VerifyExecution(tx *Transaction, block *Block, ws WorldState) (bool, error)
 // simulateExecution simulate execution and return gasUsed, executionResult and executionErr, sysErr if occurred.
 func (tx *Transaction) simulateExecution(block *Block) (*SimulateResult, error) {
 	// hash is necessary in nvm
-	hash, err := tx.calHash()
+	hash, err := tx.fieldsHash()
 	if err != nil {
 		return nil, err
 	}
@@ -639,7 +1748,10 @@ func (tx *Transaction) Sign(signature keystore.Signature) error {
 	if signature == nil {
 		return ErrNilArgument
 	}
-	hash, err := tx.calHash()
+	if err := crypto.CheckAlgorithm(signature.Algorithm()); err != nil {
+		return err
+	}
+	hash, err := tx.fieldsHash()
 	if err != nil {
 		return err
 	}
@@ -647,34 +1759,322 @@ func (tx *Transaction) Sign(signature keystore.Signature) error {
 	if err != nil {
 		return err
 	}
+	// the signer is recovered before the tx is set, so a failed Sign leaves it unsigned.
+	var signer *Address
+	if signingAuditHook != nil {
+		if signer, err = RecoverSignerFromSignature(signature.Algorithm(), hash, sign); err != nil {
+			return err
+		}
+	}
 	tx.hash = hash
 	tx.alg = signature.Algorithm()
 	tx.sign = sign
+	tx.rawBytes = nil
+	tx.frozen = true
+
+	if signingAuditHook != nil {
+		signingAuditHook(tx, signer, time.Now())
+	}
+	return nil
+}
+
+// SignWithRawKey sign transaction with a raw private key scalar instead of a keystore key.
+func (tx *Transaction) SignWithRawKey(priv []byte, alg keystore.Algorithm) error {
+	if err := crypto.CheckAlgorithm(alg); err != nil {
+		return err
+	}
+	// the scalar of secp256k1 is 32 bytes, range check is done in key decode.
+	if len(priv) != 32 {
+		return ErrInvalidPrivateKey
+	}
+
+	// copy the scalar, the key is cleared after signing.
+	seckey := make([]byte, len(priv))
+	copy(seckey, priv)
+	key, err := crypto.NewPrivateKey(alg, seckey)
+	if err != nil {
+		return ErrInvalidPrivateKey
+	}
+	defer key.Clear()
+
+	signature, err := crypto.NewSignature(alg)
+	if err != nil {
+		return err
+	}
+	if err := signature.InitSign(key); err != nil {
+		return err
+	}
+	return tx.Sign(signature)
+}
+
+// SignWithHDPath derive the key at the bip32 path from the hd seed, set from to its address and sign the tx.
+func (tx *Transaction) SignWithHDPath(seed []byte, path string, alg keystore.Algorithm) error {
+	// bip32 derivation is defined on secp256k1 only.
+	if alg != keystore.SECP256K1 {
+		return crypto.ErrAlgorithmInvalid
+	}
+	seckey, err := secp256k1.DeriveHDPrivateKey(seed, path)
+	if err != nil {
+		return err
+	}
+	pub, err := secp256k1.GetPublicKey(seckey)
+	if err != nil {
+		return err
+	}
+	from, err := NewAddressFromPublicKey(pub)
+	if err != nil {
+		return err
+	}
+	// from is hashed, so it can't be changed on a signed tx.
+	if tx.frozen {
+		return ErrTransactionFrozen
+	}
+	tx.from = from
+	tx.cachedHash = nil
+	return tx.SignWithRawKey(seckey, alg)
+}
+
+// TxIndexError is the error of the tx at Index of a batch.
+type TxIndexError struct {
+	Index int
+	Err   error
+}
+
+func (e *TxIndexError) Error() string {
+	return fmt.Sprintf("transaction %d: %s", e.Index, e.Err)
+}
+
+// TxHashError is the error of the tx with Hash in a batch.
+type TxHashError struct {
+	Hash byteutils.Hash
+	Err  error
+}
+
+func (e *TxHashError) Error() string {
+	return fmt.Sprintf("transaction %s: %s", e.Hash, e.Err)
+}
+
+// Unwrap return the error of the tx.
+func (e *TxHashError) Unwrap() error {
+	return e.Err
+}
+
+// SignEach sign each tx with the key of its sender unlocked in ks, using the algorithm algFor choose for the sender.
+// it stops at the first tx failing to sign and return a *TxIndexError, the txs before it stay signed.
+func (txs Transactions) SignEach(ks *keystore.Keystore, algFor func(*Address) keystore.Algorithm) error {
+	if ks == nil || algFor == nil {
+		return ErrNilArgument
+	}
+	for i, tx := range txs {
+		if err := tx.signWithKeystore(ks, algFor(tx.from)); err != nil {
+			return &TxIndexError{Index: i, Err: err}
+		}
+	}
+	return nil
+}
+
+func (tx *Transaction) signWithKeystore(ks *keystore.Keystore, alg keystore.Algorithm) error {
+	key, err := ks.GetUnlocked(tx.from.String())
+	if err != nil {
+		return err
+	}
+	privKey, ok := key.(keystore.PrivateKey)
+	if !ok {
+		return ErrInvalidPrivateKey
+	}
+	signature, err := crypto.NewSignature(alg)
+	if err != nil {
+		return err
+	}
+	if err := signature.InitSign(privKey); err != nil {
+		return err
+	}
+	return tx.Sign(signature)
+}
+
+// SignWithSigner set tx.from to the address of the signer's public key and sign tx with the signer,
+// the sign must recover to that address, tx is unchanged on error.
+func (tx *Transaction) SignWithSigner(s Signer, alg keystore.Algorithm) error {
+	if s == nil || s.Public() == nil {
+		return ErrNilArgument
+	}
+	// from is hashed, so it can't be changed on a signed tx.
+	if tx.frozen {
+		return ErrTransactionFrozen
+	}
+	if err := crypto.CheckAlgorithm(alg); err != nil {
+		return err
+	}
+	pub, err := s.Public().Encoded()
+	if err != nil {
+		return err
+	}
+	from, err := NewAddressFromPublicKey(pub)
+	if err != nil {
+		return err
+	}
+
+	prevFrom := tx.from
+	tx.from = from
+	hash, err := tx.calHash()
+	if err != nil {
+		tx.from = prevFrom
+		return err
+	}
+	sign, err := s.Sign(hash)
+	if err != nil {
+		tx.from = prevFrom
+		return err
+	}
+	signer, err := RecoverSignerFromSignature(alg, hash, sign)
+	if err != nil {
+		tx.from = prevFrom
+		return err
+	}
+	if !from.Equals(signer) {
+		tx.from = prevFrom
+		return ErrInvalidTransactionSigner
+	}
+	tx.hash = hash
+	tx.cacheHash(hash)
+	tx.alg = alg
+	tx.sign = sign
+	tx.rawBytes = nil
+	tx.frozen = true
+
+	if signingAuditHook != nil {
+		signingAuditHook(tx, signer, time.Now())
+	}
+	return nil
+}
+
+// ValidateStoredHash recompute the hash and compare it with the stored one, signature is not verified.
+func (tx *Transaction) ValidateStoredHash() error {
+	wantedHash, err := tx.calHash()
+	if err != nil {
+		return err
+	}
+	tx.cacheHash(wantedHash)
+	if !wantedHash.Equals(tx.hash) {
+		return ErrHashMismatch
+	}
+	return nil
+}
+
+// VerifyContractSignature verify the tx sent from a contract wallet, the hash is checked
+// and the sign is validated by the validator instead of key recovery.
+func (tx *Transaction) VerifyContractSignature(chainID uint32, validator ContractSignatureValidator) error {
+	if validator == nil {
+		return ErrNilArgument
+	}
+	if tx.from.Type() != ContractAddress {
+		return ErrNotContractSender
+	}
+	if tx.chainID != chainID {
+		return ErrInvalidChainID
+	}
+	if err := tx.ValidateStoredHash(); err != nil {
+		return err
+	}
+	ok, err := validator.IsValid(tx.from, tx.hash, tx.sign)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return ErrInvalidSignature
+	}
 	return nil
 }
 
 // VerifyIntegrity return transaction verify result, including Hash and Signature.
 func (tx *Transaction) VerifyIntegrity(chainID uint32) error {
+	if err := tx.verifyChainAndHash(chainID); err != nil {
+		return err
+	}
+
+	// check Signature.
+	return tx.verifySign()
+
+}
+
+func (tx *Transaction) verifyChainAndHash(chainID uint32) error {
 	// check ChainID.
 	if tx.chainID != chainID {
 		return ErrInvalidChainID
 	}
 
-	// check Hash.
+	if err := tx.checkForkMarker(); err != nil {
+		return err
+	}
+
+	// check the preimage size before hashing a huge payload.
+	if tx.PreimageSize() > MaxTransactionPreimageSize {
+		return ErrPreimageTooLarge
+	}
+
+	// check Hash, the cached hash is not trusted.
 	wantedHash, err := tx.calHash()
 	if err != nil {
 		return err
 	}
+	tx.cacheHash(wantedHash)
 	if wantedHash.Equals(tx.hash) == false {
 		return ErrInvalidTransactionHash
 	}
+	return nil
+}
+
+// VerifyWithHash return transaction verify result with a trusted precomputed hash,
+// the hash must equal tx.hash, then only Signature is checked.
+func (tx *Transaction) VerifyWithHash(hash byteutils.Hash, chainID uint32) error {
+	// check ChainID.
+	if tx.chainID != chainID {
+		return ErrInvalidChainID
+	}
+
+	if err := tx.checkForkMarker(); err != nil {
+		return err
+	}
+
+	// check Hash.
+	if !hash.Equals(tx.hash) {
+		return ErrHashMismatch
+	}
 
 	// check Signature.
 	return tx.verifySign()
+}
+
+// AssertNonceSigned check tx carries the expected nonce and the signature is valid over a hash
+// computed from it, a defensive check for paths that must not accept a swapped nonce.
+func (tx *Transaction) AssertNonceSigned(expected uint64) error {
+	if tx.nonce != expected {
+		return ErrNonceNotSigned
+	}
 
+	// the signed hash must be the one of the current fields, including nonce.
+	wantedHash, err := tx.calHash()
+	if err != nil {
+		return err
+	}
+	if !wantedHash.Equals(tx.hash) {
+		return ErrNonceNotSigned
+	}
+	return tx.verifySign()
 }
 
 func (tx *Transaction) verifySign() error {
+	if err := crypto.CheckAlgorithm(tx.alg); err != nil {
+		return err
+	}
+	// only supported algorithms are counted, so peers can't grow the stats with arbitrary keys.
+	recordAlgorithm(tx.alg)
+	if !tx.from.SupportsAlgorithm(tx.alg) {
+		return ErrAlgorithmAddressMismatch
+	}
+	if len(tx.ephemeralPubKey) > 0 {
+		return tx.verifyEphemeralSign()
+	}
 	signer, err := RecoverSignerFromSignature(tx.alg, tx.hash, tx.sign)
 	if err != nil {
 		return err
@@ -765,7 +2165,7 @@ func AcceptTransaction(tx *Transaction, ws WorldState) (bool, error) {
 	if err != nil {
 		return true, err
 	}
-	if err := ws.PutTx(tx.hash, txBytes); err != nil {
+	if err := ws.PutTx(tx.TrieKey(), txBytes); err != nil {
 		return true, err
 	}
 	// incre nonce
@@ -798,9 +2198,75 @@ func GetTransaction(hash byteutils.Hash, ws WorldState) (*Transaction, error) {
 	return tx, nil
 }
 
+// hashField is an optional field of the tx hash, written as its tag, 4 bytes length and value,
+// so a value can't be moved into another optional field without changing the hash.
+type hashField struct {
+	tag   byte // proto field number
+	value []byte
+}
+
+// optionalHashFields return the optional fields set in tx, in proto field order.
+func (tx *Transaction) optionalHashFields() []hashField {
+	var fields []hashField
+	if len(tx.salt) > 0 {
+		fields = append(fields, hashField{13, tx.salt})
+	}
+	if tx.tipRecipient != nil {
+		fields = append(fields, hashField{14, tx.tipRecipient.address})
+	}
+	if len(tx.ephemeralPubKey) > 0 {
+		fields = append(fields, hashField{15, tx.ephemeralPubKey})
+	}
+	if tx.powNonce != 0 {
+		fields = append(fields, hashField{16, byteutils.FromUint64(tx.powNonce)})
+	}
+	if tx.validFrom != 0 {
+		fields = append(fields, hashField{18, byteutils.FromInt64(tx.validFrom)})
+	}
+	if tx.deadline != 0 {
+		fields = append(fields, hashField{19, byteutils.FromInt64(tx.deadline)})
+	}
+	if len(tx.forkMarker) > 0 {
+		fields = append(fields, hashField{20, tx.forkMarker})
+	}
+	return fields
+}
+
+// fieldsHash return the hash of the current fields for signing, computed once and cached until a hashed field
+// is set or the hash domain changes. setters, Clone and FromProto drop the cache, verification never reads it
+// and always recomputes the hash.
+func (tx *Transaction) fieldsHash() (byteutils.Hash, error) {
+	if tx.cachedHash != nil && bytes.Equal(tx.cachedDomain, txHashDomain) {
+		return tx.cachedHash, nil
+	}
+	hash, err := tx.calHash()
+	if err != nil {
+		return nil, err
+	}
+	tx.cacheHash(hash)
+	return hash, nil
+}
+
+// cacheHash set hash as the hash of the current fields under the current hash domain.
+func (tx *Transaction) cacheHash(hash byteutils.Hash) {
+	tx.cachedHash, tx.cachedDomain = hash, txHashDomain
+}
+
 // HashTransaction hash the transaction.
 func (tx *Transaction) calHash() (byteutils.Hash, error) {
+	switch tx.hashAlg {
+	case TxHashSha3256:
+	case TxHashPoseidon:
+		return HashTransactionPoseidon(tx)
+	default:
+		return nil, ErrUnsupportedHashAlgorithm
+	}
+
 	hasher := sha3.New256()
+	// hash of main chain txs is unchanged.
+	if len(txHashDomain) > 0 {
+		hasher.Write(txHashDomain)
+	}
 
 	value, err := tx.value.ToFixedSizeByteSlice()
 	if err != nil {
@@ -828,6 +2294,12 @@ func (tx *Transaction) calHash() (byteutils.Hash, error) {
 	hasher.Write(byteutils.FromUint32(tx.chainID))
 	hasher.Write(gasPrice)
 	hasher.Write(gasLimit)
+	// optional fields are only hashed when set, so the hash of txs not setting them is unchanged.
+	for _, field := range tx.optionalHashFields() {
+		hasher.Write([]byte{field.tag})
+		hasher.Write(byteutils.FromUint32(uint32(len(field.value))))
+		hasher.Write(field.value)
+	}
 
 	return hasher.Sum(nil), nil
 }
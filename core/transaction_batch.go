@@ -0,0 +1,135 @@
+// Copyright (C) 2017 go-nebulas authors
+//
+// This file is part of the go-nebulas library.
+//
+// the go-nebulas library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// the go-nebulas library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with the go-nebulas library.  If not, see <http://www.gnu.org/licenses/>.
+//
+
+package core
+
+import (
+	"bufio"
+	"encoding/binary"
+	"io"
+	"sync"
+
+	"github.com/gogo/protobuf/proto"
+	"github.com/alexlisong/go-nebulas/core/pb"
+)
+
+var (
+	// MaxTransactionsPerBatch max count of txs in a batch read from stream
+	MaxTransactionsPerBatch uint64 = 10000
+
+	// MaxBytesPerTransaction max length of a serialized tx in a batch read from stream
+	MaxBytesPerTransaction uint64 = uint64(MaxDataPayLoadLength) + 4*1024
+)
+
+// WriteTransactions write txs into stream, the batch is encoded as
+// uvarint(count) followed by uvarint(len(tx)) + proto bytes of each tx.
+func WriteTransactions(w io.Writer, txs Transactions) error {
+	buf := make([]byte, binary.MaxVarintLen64)
+	n := binary.PutUvarint(buf, uint64(len(txs)))
+	if _, err := w.Write(buf[:n]); err != nil {
+		return err
+	}
+	for _, tx := range txs {
+		pbTx, err := tx.ToProto()
+		if err != nil {
+			return err
+		}
+		data, err := proto.Marshal(pbTx)
+		if err != nil {
+			return err
+		}
+		n = binary.PutUvarint(buf, uint64(len(data)))
+		if _, err := w.Write(buf[:n]); err != nil {
+			return err
+		}
+		if _, err := w.Write(data); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ReadTransactions read txs written by WriteTransactions from stream.
+// Length prefixes are checked against MaxTransactionsPerBatch and MaxBytesPerTransaction
+// before any allocation, an oversized prefix returns ErrBatchTooLarge.
+func ReadTransactions(r io.Reader) (Transactions, error) {
+	br, ok := r.(io.ByteReader)
+	if !ok {
+		buffered := bufio.NewReader(r)
+		r, br = buffered, buffered
+	}
+
+	count, err := binary.ReadUvarint(br)
+	if err != nil {
+		return nil, err
+	}
+	if count > MaxTransactionsPerBatch {
+		return nil, ErrBatchTooLarge
+	}
+
+	txs := make(Transactions, 0, count)
+	for i := uint64(0); i < count; i++ {
+		length, err := binary.ReadUvarint(br)
+		if err != nil {
+			return nil, err
+		}
+		if length > MaxBytesPerTransaction {
+			return nil, ErrBatchTooLarge
+		}
+		data := make([]byte, length)
+		if _, err := io.ReadFull(r, data); err != nil {
+			return nil, err
+		}
+		pbTx := new(corepb.Transaction)
+		if err := proto.Unmarshal(data, pbTx); err != nil {
+			return nil, err
+		}
+		tx := new(Transaction)
+		if err := tx.FromProto(pbTx); err != nil {
+			return nil, err
+		}
+		txs = append(txs, tx)
+	}
+	return txs, nil
+}
+
+// ToProtoBatch converts txs to proto txs like ToProto, reusing the *corepb.Transaction of pool
+// to save an allocation per tx in hot encoding paths. all fields of a reused message are overwritten,
+// the caller put the messages back into pool once they are marshalled and must not keep them.
+// the messages are put back into pool on error.
+func (txs Transactions) ToProtoBatch(pool *sync.Pool) ([]proto.Message, error) {
+	if pool == nil {
+		return nil, ErrNilArgument
+	}
+	msgs := make([]proto.Message, 0, len(txs))
+	for _, tx := range txs {
+		msg, ok := pool.Get().(*corepb.Transaction)
+		if !ok || msg == nil {
+			msg = new(corepb.Transaction)
+		}
+		if err := tx.fillProto(msg); err != nil {
+			pool.Put(msg)
+			for _, m := range msgs {
+				pool.Put(m)
+			}
+			return nil, err
+		}
+		msgs = append(msgs, msg)
+	}
+	return msgs, nil
+}
@@ -0,0 +1,127 @@
+// Copyright (C) 2017 go-nebulas authors
+//
+// This file is part of the go-nebulas library.
+//
+// the go-nebulas library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// the go-nebulas library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with the go-nebulas library.  If not, see <http://www.gnu.org/licenses/>.
+//
+
+package core
+
+import (
+	"bytes"
+	"encoding/binary"
+	"sync"
+	"testing"
+
+	"github.com/alexlisong/go-nebulas/core/pb"
+	"github.com/gogo/protobuf/proto"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReadTransactions(t *testing.T) {
+	txs := Transactions{}
+	for i := 0; i < 3; i++ {
+		tx := mockNormalTransaction(1, uint64(i+1))
+		assert.Nil(t, signTx(tx))
+		txs = append(txs, tx)
+	}
+
+	buf := new(bytes.Buffer)
+	assert.Nil(t, WriteTransactions(buf, txs))
+	ntxs, err := ReadTransactions(buf)
+	assert.Nil(t, err)
+	assert.Equal(t, len(txs), len(ntxs))
+	for i, tx := range txs {
+		assert.Equal(t, tx.Hash(), ntxs[i].Hash())
+		assert.Nil(t, ntxs[i].VerifyIntegrity(1))
+	}
+}
+
+func TestReadTransactions_Oversized(t *testing.T) {
+	prefix := make([]byte, binary.MaxVarintLen64)
+
+	// crafted tx count.
+	n := binary.PutUvarint(prefix, MaxTransactionsPerBatch+1)
+	_, err := ReadTransactions(bytes.NewReader(prefix[:n]))
+	assert.Equal(t, ErrBatchTooLarge, err)
+
+	// crafted tx length.
+	n = binary.PutUvarint(prefix, 1)
+	batch := append([]byte{}, prefix[:n]...)
+	n = binary.PutUvarint(prefix, 1<<62)
+	batch = append(batch, prefix[:n]...)
+	_, err = ReadTransactions(bytes.NewReader(batch))
+	assert.Equal(t, ErrBatchTooLarge, err)
+
+	// truncated stream.
+	n = binary.PutUvarint(prefix, 1)
+	batch = append([]byte{}, prefix[:n]...)
+	n = binary.PutUvarint(prefix, 64)
+	batch = append(batch, prefix[:n]...)
+	_, err = ReadTransactions(bytes.NewReader(batch))
+	assert.NotNil(t, err)
+}
+
+func TestTransactions_ToProtoBatch(t *testing.T) {
+	extended := mockNormalTransaction(1, 1)
+	extended.SetTipRecipient(mockAddress())
+	assert.Nil(t, extended.SetReplaySalt(mockReplaySalt()))
+	extended.SetForkMarker([]byte("fork-a"))
+	assert.Nil(t, signTx(extended))
+
+	// the pooled message of the extended tx is reused by the plain one.
+	pool := &sync.Pool{}
+	msgs, err := Transactions{extended}.ToProtoBatch(pool)
+	assert.Nil(t, err)
+	pool.Put(msgs[0])
+
+	txs := mockSignedTransactions(t, 3)
+	msgs, err = txs.ToProtoBatch(pool)
+	assert.Nil(t, err)
+	assert.Equal(t, len(txs), len(msgs))
+	for i, tx := range txs {
+		want, err := tx.ToProto()
+		assert.Nil(t, err)
+		assert.Equal(t, want, msgs[i])
+	}
+
+	_, err = txs.ToProtoBatch(nil)
+	assert.Equal(t, ErrNilArgument, err)
+}
+
+func BenchmarkTransactions_ToProto(b *testing.B) {
+	txs := mockBenchmarkTransactions(b)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		for _, tx := range txs {
+			msg, _ := tx.ToProto()
+			proto.Marshal(msg)
+		}
+	}
+}
+
+func BenchmarkTransactions_ToProtoBatch(b *testing.B) {
+	txs := mockBenchmarkTransactions(b)
+	pool := &sync.Pool{New: func() interface{} { return new(corepb.Transaction) }}
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		msgs, _ := txs.ToProtoBatch(pool)
+		for _, msg := range msgs {
+			proto.Marshal(msg)
+			pool.Put(msg)
+		}
+	}
+}
@@ -0,0 +1,75 @@
+// Copyright (C) 2017 go-nebulas authors
+//
+// This file is part of the go-nebulas library.
+//
+// the go-nebulas library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// the go-nebulas library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with the go-nebulas library.  If not, see <http://www.gnu.org/licenses/>.
+//
+
+package core
+
+import (
+	"github.com/alexlisong/go-nebulas/crypto/hash"
+)
+
+// AddressBloomLength is the byte length of the address bloom of a batch, 2048 bits.
+const AddressBloomLength = 256
+
+// addressBloomHashes is the number of bits set per address.
+const addressBloomHashes = 3
+
+// AddressBloom build a bloom filter over the from and to addresses of the txs,
+// a light client test it with BloomMayContain before fetching the txs.
+// the filter has 2048 bits and sets 3 of them per address, the false positive rate
+// for n distinct addresses is about (1 - e^(-3n/2048))^3: ~0.25% for 100, ~4.5% for 300
+// and ~45% for 1000 addresses, so large batches should be split before being filtered.
+func (txs Transactions) AddressBloom() []byte {
+	bloom := make([]byte, AddressBloomLength)
+	for _, tx := range txs {
+		for _, addr := range []*Address{tx.from, tx.to} {
+			if addr != nil {
+				addToBloom(bloom, addr.address)
+			}
+		}
+	}
+	return bloom
+}
+
+// BloomMayContain check if the address may be in the bloom, false means it's surely not.
+func BloomMayContain(bloom []byte, addr *Address) bool {
+	if addr == nil || len(bloom) != AddressBloomLength {
+		return false
+	}
+	for _, bit := range bloomBits(addr.address) {
+		if bloom[bit/8]&(1<<(bit%8)) == 0 {
+			return false
+		}
+	}
+	return true
+}
+
+func addToBloom(bloom []byte, data []byte) {
+	for _, bit := range bloomBits(data) {
+		bloom[bit/8] |= 1 << (bit % 8)
+	}
+}
+
+// bloomBits take the low 11 bits of the first byte pairs of the sha3 of data.
+func bloomBits(data []byte) []uint {
+	h := hash.Sha3256(data)
+	bits := make([]uint, addressBloomHashes)
+	for i := range bits {
+		bits[i] = (uint(h[2*i])<<8 | uint(h[2*i+1])) % (AddressBloomLength * 8)
+	}
+	return bits
+}
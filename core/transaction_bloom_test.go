@@ -0,0 +1,52 @@
+// Copyright (C) 2017 go-nebulas authors
+//
+// This file is part of the go-nebulas library.
+//
+// the go-nebulas library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// the go-nebulas library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with the go-nebulas library.  If not, see <http://www.gnu.org/licenses/>.
+//
+
+package core
+
+import (
+	"testing"
+
+	"github.com/alexlisong/go-nebulas/util/byteutils"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTransactions_AddressBloom(t *testing.T) {
+	a, b, c, d := mockAddress(), mockAddress(), mockAddress(), mockAddress()
+	txs := Transactions{mockTransfer(a, b, 1), mockTransfer(c, d, 1), mockTransfer(a, d, 2)}
+	bloom := txs.AddressBloom()
+	assert.Equal(t, AddressBloomLength, len(bloom))
+	assert.Equal(t, bloom, txs.AddressBloom())
+	for _, addr := range []*Address{a, b, c, d} {
+		assert.True(t, BloomMayContain(bloom, addr))
+	}
+
+	// 4 addresses set at most 12 of 2048 bits, false positives are about 1e-7.
+	positives := 0
+	for i := 0; i < 100; i++ {
+		other, err := newAddress(AccountAddress, byteutils.FromUint64(uint64(i)))
+		assert.Nil(t, err)
+		if BloomMayContain(bloom, other) {
+			positives++
+		}
+	}
+	assert.True(t, positives < 2)
+
+	assert.False(t, BloomMayContain(Transactions{}.AddressBloom(), a))
+	assert.False(t, BloomMayContain(bloom[1:], a))
+	assert.False(t, BloomMayContain(bloom, nil))
+}
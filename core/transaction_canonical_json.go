@@ -0,0 +1,121 @@
+// Copyright (C) 2017 go-nebulas authors
+//
+// This file is part of the go-nebulas library.
+//
+// the go-nebulas library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// the go-nebulas library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with the go-nebulas library.  If not, see <http://www.gnu.org/licenses/>.
+//
+
+package core
+
+import (
+	"encoding/json"
+	"strconv"
+
+	"github.com/alexlisong/go-nebulas/crypto"
+	"github.com/alexlisong/go-nebulas/crypto/hash"
+	"github.com/alexlisong/go-nebulas/crypto/keystore"
+	"github.com/alexlisong/go-nebulas/util/byteutils"
+)
+
+// CanonicalJSON return the json of the signed fields of tx for json-based signing flows,
+// keys are sorted and all values are strings, integers in decimal and bytes in hex.
+// optional fields are only present when set, hash and sign are not included.
+func (tx *Transaction) CanonicalJSON() ([]byte, error) {
+	// encoding/json writes map keys in sorted order.
+	return json.Marshal(tx.canonicalFields())
+}
+
+// canonicalFields return the signed fields of tx in the string form of CanonicalJSON.
+func (tx *Transaction) canonicalFields() map[string]string {
+	fields := map[string]string{
+		"chainID":   strconv.FormatUint(uint64(tx.chainID), 10),
+		"from":      tx.from.String(),
+		"to":        tx.to.String(),
+		"value":     tx.value.String(),
+		"nonce":     strconv.FormatUint(tx.nonce, 10),
+		"timestamp": strconv.FormatInt(tx.timestamp, 10),
+		"type":      tx.Type(),
+		"data":      byteutils.Hex(tx.Data()),
+		"gasPrice":  tx.gasPrice.String(),
+		"gasLimit":  tx.gasLimit.String(),
+	}
+	if len(tx.salt) > 0 {
+		fields["salt"] = byteutils.Hex(tx.salt)
+	}
+	if tx.tipRecipient != nil {
+		fields["tipRecipient"] = tx.tipRecipient.String()
+	}
+	if len(tx.ephemeralPubKey) > 0 {
+		fields["ephemeralPubKey"] = byteutils.Hex(tx.ephemeralPubKey)
+	}
+	if tx.powNonce != 0 {
+		fields["powNonce"] = strconv.FormatUint(tx.powNonce, 10)
+	}
+	if tx.hashAlg != TxHashSha3256 {
+		fields["hashAlg"] = strconv.FormatUint(uint64(tx.hashAlg), 10)
+	}
+	if tx.validFrom != 0 {
+		fields["validFrom"] = strconv.FormatInt(tx.validFrom, 10)
+	}
+	if tx.deadline != 0 {
+		fields["deadline"] = strconv.FormatInt(tx.deadline, 10)
+	}
+	if len(tx.forkMarker) > 0 {
+		fields["forkMarker"] = byteutils.Hex(tx.forkMarker)
+	}
+	return fields
+}
+
+// CanonicalJSONHash return the sha3-256 of the canonical json.
+func (tx *Transaction) CanonicalJSONHash() (byteutils.Hash, error) {
+	data, err := tx.CanonicalJSON()
+	if err != nil {
+		return nil, err
+	}
+	return hash.Sha3256(data), nil
+}
+
+// SignCanonicalJSON sign the canonical json hash of tx, the tx sign is not changed.
+func (tx *Transaction) SignCanonicalJSON(signature keystore.Signature) ([]byte, error) {
+	if signature == nil {
+		return nil, ErrNilArgument
+	}
+	if err := crypto.CheckAlgorithm(signature.Algorithm()); err != nil {
+		return nil, err
+	}
+	h, err := tx.CanonicalJSONHash()
+	if err != nil {
+		return nil, err
+	}
+	return signature.Sign(h)
+}
+
+// VerifyCanonicalJSON check the sign of the canonical json hash is made by tx.from.
+func (tx *Transaction) VerifyCanonicalJSON(alg keystore.Algorithm, sign []byte) error {
+	if err := crypto.CheckAlgorithm(alg); err != nil {
+		return err
+	}
+	h, err := tx.CanonicalJSONHash()
+	if err != nil {
+		return err
+	}
+	signer, err := RecoverSignerFromSignature(alg, h, sign)
+	if err != nil {
+		return err
+	}
+	if !tx.from.Equals(signer) {
+		return ErrInvalidTransactionSigner
+	}
+	return nil
+}
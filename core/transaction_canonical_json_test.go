@@ -0,0 +1,93 @@
+// Copyright (C) 2017 go-nebulas authors
+//
+// This file is part of the go-nebulas library.
+//
+// the go-nebulas library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// the go-nebulas library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with the go-nebulas library.  If not, see <http://www.gnu.org/licenses/>.
+//
+
+package core
+
+import (
+	"encoding/json"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/alexlisong/go-nebulas/crypto"
+	"github.com/alexlisong/go-nebulas/crypto/keystore"
+	"github.com/alexlisong/go-nebulas/util"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTransaction_CanonicalJSON(t *testing.T) {
+	from, to := mockAddress(), mockAddress()
+	value, _ := util.NewUint128FromString("1000000000000000000000")
+	build := func() *Transaction {
+		tx, _ := NewTransaction(1, from, to, value, 7, TxPayloadBinaryType, []byte("memo"), TransactionGasPrice, TransactionMaxGas)
+		tx.timestamp = 1530000000
+		return tx
+	}
+
+	tx1, tx2 := build(), build()
+	tx2.SetAnnotation("label", "ignored")
+	data1, err := tx1.CanonicalJSON()
+	assert.Nil(t, err)
+	data2, err := tx2.CanonicalJSON()
+	assert.Nil(t, err)
+	assert.Equal(t, data1, data2)
+	assert.Contains(t, string(data1), `"value":"1000000000000000000000"`)
+	assert.Contains(t, string(data1), `"nonce":"7"`)
+	assert.Contains(t, string(data1), `"data":"6d656d6f"`)
+	assert.NotContains(t, string(data1), "salt")
+
+	// keys are sorted
+	var fields map[string]string
+	assert.Nil(t, json.Unmarshal(data1, &fields))
+	keys := make([]string, 0, len(fields))
+	for k := range fields {
+		keys = append(keys, `"`+k+`"`)
+	}
+	sort.Strings(keys)
+	last := -1
+	for _, k := range keys {
+		idx := strings.Index(string(data1), k)
+		assert.True(t, idx > last, k)
+		last = idx
+	}
+
+	// signed fields change the json
+	assert.Nil(t, tx2.SetReplaySalt(mockReplaySalt()))
+	data2, err = tx2.CanonicalJSON()
+	assert.Nil(t, err)
+	assert.NotEqual(t, data1, data2)
+	assert.Contains(t, string(data2), `"salt":"`)
+}
+
+func TestTransaction_SignCanonicalJSON(t *testing.T) {
+	tx := mockNormalTransaction(1, 1)
+	key, _ := keystore.DefaultKS.GetUnlocked(tx.from.String())
+	signature, _ := crypto.NewSignature(keystore.SECP256K1)
+	assert.Nil(t, signature.InitSign(key.(keystore.PrivateKey)))
+
+	sign, err := tx.SignCanonicalJSON(signature)
+	assert.Nil(t, err)
+	assert.Nil(t, tx.sign)
+	assert.Nil(t, tx.VerifyCanonicalJSON(keystore.SECP256K1, sign))
+
+	tx.nonce++
+	assert.Equal(t, ErrInvalidTransactionSigner, tx.VerifyCanonicalJSON(keystore.SECP256K1, sign))
+	assert.Equal(t, crypto.ErrAlgorithmInvalid, tx.VerifyCanonicalJSON(keystore.Algorithm(255), sign))
+	_, err = tx.SignCanonicalJSON(nil)
+	assert.Equal(t, ErrNilArgument, err)
+}
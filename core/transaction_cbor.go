@@ -0,0 +1,380 @@
+// Copyright (C) 2017 go-nebulas authors
+//
+// This file is part of the go-nebulas library.
+//
+// the go-nebulas library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// the go-nebulas library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with the go-nebulas library.  If not, see <http://www.gnu.org/licenses/>.
+//
+
+package core
+
+import (
+	"bytes"
+	"math"
+
+	"github.com/alexlisong/go-nebulas/core/pb"
+)
+
+// cbor major types
+const (
+	cborUint   = 0
+	cborNegint = 1
+	cborBytes  = 2
+	cborText   = 3
+	cborMap    = 5
+)
+
+// MarshalCBOR return the deterministic CBOR (RFC 8949 core deterministic encoding) of tx.
+// tx is a map keyed by the field numbers of the proto Transaction, fields not set are omitted:
+//
+//	1 hash             bytes
+//	2 from             bytes, 26 bytes address
+//	3 to               bytes, 26 bytes address
+//	4 value            bytes, 16 bytes big-endian
+//	5 nonce            uint
+//	6 timestamp        int
+//	7 data             map {1 type: text, 2 payload: bytes}, always present
+//	8 chainID          uint
+//	9 gasPrice         bytes, 16 bytes big-endian
+//	10 gasLimit        bytes, 16 bytes big-endian
+//	11 alg             uint
+//	12 sign            bytes
+//	13 salt            bytes
+//	14 tipRecipient    bytes, 26 bytes address
+//	15 ephemeralPubKey bytes
+//	16 powNonce        uint
+//	17 hashAlg         uint
+//	18 validFrom       int
+//	19 deadline        int
+//	20 forkMarker      bytes
+func (tx *Transaction) MarshalCBOR() ([]byte, error) {
+	msg, err := tx.ToProto()
+	if err != nil {
+		return nil, err
+	}
+	pbTx := msg.(*corepb.Transaction)
+
+	e := new(cborEncoder)
+	var fields int
+	count := func(set bool) {
+		if set {
+			fields++
+		}
+	}
+	count(len(pbTx.Hash) > 0)
+	count(len(pbTx.From) > 0)
+	count(len(pbTx.To) > 0)
+	count(len(pbTx.Value) > 0)
+	count(pbTx.Nonce != 0)
+	count(pbTx.Timestamp != 0)
+	count(true)
+	count(pbTx.ChainId != 0)
+	count(len(pbTx.GasPrice) > 0)
+	count(len(pbTx.GasLimit) > 0)
+	count(pbTx.Alg != 0)
+	count(len(pbTx.Sign) > 0)
+	count(len(pbTx.Salt) > 0)
+	count(len(pbTx.TipRecipient) > 0)
+	count(len(pbTx.EphemeralPubKey) > 0)
+	count(pbTx.PowNonce != 0)
+	count(pbTx.HashAlg != 0)
+	count(pbTx.ValidFrom != 0)
+	count(pbTx.Deadline != 0)
+	count(len(pbTx.ForkMarker) > 0)
+
+	e.head(cborMap, uint64(fields))
+	e.bytesField(1, pbTx.Hash)
+	e.bytesField(2, pbTx.From)
+	e.bytesField(3, pbTx.To)
+	e.bytesField(4, pbTx.Value)
+	e.uintField(5, pbTx.Nonce)
+	e.intField(6, pbTx.Timestamp)
+	e.head(cborUint, 7)
+	e.head(cborMap, 2)
+	e.head(cborUint, 1)
+	e.text(pbTx.Data.GetType())
+	e.head(cborUint, 2)
+	e.bytes(pbTx.Data.GetPayload())
+	e.uintField(8, uint64(pbTx.ChainId))
+	e.bytesField(9, pbTx.GasPrice)
+	e.bytesField(10, pbTx.GasLimit)
+	e.uintField(11, uint64(pbTx.Alg))
+	e.bytesField(12, pbTx.Sign)
+	e.bytesField(13, pbTx.Salt)
+	e.bytesField(14, pbTx.TipRecipient)
+	e.bytesField(15, pbTx.EphemeralPubKey)
+	e.uintField(16, pbTx.PowNonce)
+	e.uintField(17, uint64(pbTx.HashAlg))
+	e.intField(18, pbTx.ValidFrom)
+	e.intField(19, pbTx.Deadline)
+	e.bytesField(20, pbTx.ForkMarker)
+	return e.buf.Bytes(), nil
+}
+
+// UnmarshalCBOR set tx from the CBOR made by MarshalCBOR, only the deterministic encoding is accepted,
+// so marshalling the tx again return data byte by byte.
+func (tx *Transaction) UnmarshalCBOR(data []byte) error {
+	d := &cborDecoder{data: data}
+	n, err := d.expect(cborMap)
+	if err != nil {
+		return err
+	}
+
+	pbTx := new(corepb.Transaction)
+	for i := uint64(0); i < n; i++ {
+		key, err := d.expect(cborUint)
+		if err != nil {
+			return err
+		}
+		switch key {
+		case 1:
+			pbTx.Hash, err = d.bytes()
+		case 2:
+			pbTx.From, err = d.bytes()
+		case 3:
+			pbTx.To, err = d.bytes()
+		case 4:
+			pbTx.Value, err = d.bytes()
+		case 5:
+			pbTx.Nonce, err = d.expect(cborUint)
+		case 6:
+			pbTx.Timestamp, err = d.int()
+		case 7:
+			pbTx.Data, err = d.payloadData()
+		case 8:
+			pbTx.ChainId, err = d.uint32()
+		case 9:
+			pbTx.GasPrice, err = d.bytes()
+		case 10:
+			pbTx.GasLimit, err = d.bytes()
+		case 11:
+			pbTx.Alg, err = d.uint32()
+		case 12:
+			pbTx.Sign, err = d.bytes()
+		case 13:
+			pbTx.Salt, err = d.bytes()
+		case 14:
+			pbTx.TipRecipient, err = d.bytes()
+		case 15:
+			pbTx.EphemeralPubKey, err = d.bytes()
+		case 16:
+			pbTx.PowNonce, err = d.expect(cborUint)
+		case 17:
+			pbTx.HashAlg, err = d.uint32()
+		case 18:
+			pbTx.ValidFrom, err = d.int()
+		case 19:
+			pbTx.Deadline, err = d.int()
+		case 20:
+			pbTx.ForkMarker, err = d.bytes()
+		default:
+			return ErrInvalidCBOR
+		}
+		if err != nil {
+			return err
+		}
+	}
+	if d.pos != len(data) {
+		return ErrInvalidCBOR
+	}
+
+	ntx := new(Transaction)
+	if err := ntx.FromProto(pbTx); err != nil {
+		return err
+	}
+	canonical, err := ntx.MarshalCBOR()
+	if err != nil {
+		return err
+	}
+	if !bytes.Equal(canonical, data) {
+		return ErrNonCanonicalSerialization
+	}
+	*tx = *ntx
+	return nil
+}
+
+type cborEncoder struct {
+	buf bytes.Buffer
+}
+
+// head write the shortest form of the major type and argument.
+func (e *cborEncoder) head(major byte, n uint64) {
+	major <<= 5
+	switch {
+	case n < 24:
+		e.buf.WriteByte(major | byte(n))
+	case n <= math.MaxUint8:
+		e.buf.Write([]byte{major | 24, byte(n)})
+	case n <= math.MaxUint16:
+		e.buf.Write([]byte{major | 25, byte(n >> 8), byte(n)})
+	case n <= math.MaxUint32:
+		e.buf.Write([]byte{major | 26, byte(n >> 24), byte(n >> 16), byte(n >> 8), byte(n)})
+	default:
+		e.buf.WriteByte(major | 27)
+		for shift := uint(56); ; shift -= 8 {
+			e.buf.WriteByte(byte(n >> shift))
+			if shift == 0 {
+				break
+			}
+		}
+	}
+}
+
+func (e *cborEncoder) bytes(b []byte) {
+	e.head(cborBytes, uint64(len(b)))
+	e.buf.Write(b)
+}
+
+func (e *cborEncoder) text(s string) {
+	e.head(cborText, uint64(len(s)))
+	e.buf.WriteString(s)
+}
+
+func (e *cborEncoder) bytesField(key uint64, b []byte) {
+	if len(b) > 0 {
+		e.head(cborUint, key)
+		e.bytes(b)
+	}
+}
+
+func (e *cborEncoder) uintField(key uint64, v uint64) {
+	if v != 0 {
+		e.head(cborUint, key)
+		e.head(cborUint, v)
+	}
+}
+
+func (e *cborEncoder) intField(key uint64, v int64) {
+	if v == 0 {
+		return
+	}
+	e.head(cborUint, key)
+	if v > 0 {
+		e.head(cborUint, uint64(v))
+	} else {
+		e.head(cborNegint, uint64(-1-v))
+	}
+}
+
+type cborDecoder struct {
+	data []byte
+	pos  int
+}
+
+// next read a head, indefinite lengths are not deterministic and rejected.
+func (d *cborDecoder) next() (byte, uint64, error) {
+	if d.pos >= len(d.data) {
+		return 0, 0, ErrInvalidCBOR
+	}
+	major, info := d.data[d.pos]>>5, d.data[d.pos]&0x1f
+	d.pos++
+	if info < 24 {
+		return major, uint64(info), nil
+	}
+	if info > 27 {
+		return 0, 0, ErrInvalidCBOR
+	}
+	size := 1 << (info - 24)
+	if len(d.data)-d.pos < size {
+		return 0, 0, ErrInvalidCBOR
+	}
+	var n uint64
+	for _, b := range d.data[d.pos : d.pos+size] {
+		n = n<<8 | uint64(b)
+	}
+	d.pos += size
+	return major, n, nil
+}
+
+func (d *cborDecoder) expect(major byte) (uint64, error) {
+	m, n, err := d.next()
+	if err != nil {
+		return 0, err
+	}
+	if m != major {
+		return 0, ErrInvalidCBOR
+	}
+	return n, nil
+}
+
+func (d *cborDecoder) payload(major byte) ([]byte, error) {
+	n, err := d.expect(major)
+	if err != nil {
+		return nil, err
+	}
+	if n > uint64(len(d.data)-d.pos) {
+		return nil, ErrInvalidCBOR
+	}
+	b := make([]byte, n)
+	copy(b, d.data[d.pos:])
+	d.pos += int(n)
+	return b, nil
+}
+
+func (d *cborDecoder) bytes() ([]byte, error) {
+	return d.payload(cborBytes)
+}
+
+func (d *cborDecoder) uint32() (uint32, error) {
+	n, err := d.expect(cborUint)
+	if err != nil {
+		return 0, err
+	}
+	if n > math.MaxUint32 {
+		return 0, ErrInvalidCBOR
+	}
+	return uint32(n), nil
+}
+
+func (d *cborDecoder) int() (int64, error) {
+	m, n, err := d.next()
+	if err != nil {
+		return 0, err
+	}
+	if n > math.MaxInt64 {
+		return 0, ErrInvalidCBOR
+	}
+	switch m {
+	case cborUint:
+		return int64(n), nil
+	case cborNegint:
+		return -1 - int64(n), nil
+	}
+	return 0, ErrInvalidCBOR
+}
+
+func (d *cborDecoder) payloadData() (*corepb.Data, error) {
+	n, err := d.expect(cborMap)
+	if err != nil {
+		return nil, err
+	}
+	if n != 2 {
+		return nil, ErrInvalidCBOR
+	}
+	data := new(corepb.Data)
+	if key, err := d.expect(cborUint); err != nil || key != 1 {
+		return nil, ErrInvalidCBOR
+	}
+	typ, err := d.payload(cborText)
+	if err != nil {
+		return nil, err
+	}
+	data.Type = string(typ)
+	if key, err := d.expect(cborUint); err != nil || key != 2 {
+		return nil, ErrInvalidCBOR
+	}
+	if data.Payload, err = d.bytes(); err != nil {
+		return nil, err
+	}
+	return data, nil
+}
@@ -0,0 +1,120 @@
+// Copyright (C) 2017 go-nebulas authors
+//
+// This file is part of the go-nebulas library.
+//
+// the go-nebulas library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// the go-nebulas library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with the go-nebulas library.  If not, see <http://www.gnu.org/licenses/>.
+//
+
+package core
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func mockCBORTransactions(t *testing.T) []*Transaction {
+	normal := mockNormalTransaction(1, 1)
+	call := mockCallTransaction(1, 2, "transfer", "[1]")
+	extended := mockNormalTransaction(1, 3)
+	assert.Nil(t, extended.SetReplaySalt(mockReplaySalt()))
+	extended.SetTipRecipient(mockAddress())
+	assert.Nil(t, extended.SetValidityWindow(time.Unix(1500000000, 0), time.Unix(1500003600, 0)))
+	txs := []*Transaction{normal, call, extended}
+	for _, tx := range txs {
+		assert.Nil(t, signTx(tx))
+	}
+	return txs
+}
+
+func TestTransaction_CBORRoundTrip(t *testing.T) {
+	for _, tx := range mockCBORTransactions(t) {
+		data, err := tx.MarshalCBOR()
+		assert.Nil(t, err)
+
+		decoded := new(Transaction)
+		assert.Nil(t, decoded.UnmarshalCBOR(data))
+		assert.True(t, tx.Equals(decoded))
+		assert.Nil(t, decoded.VerifyIntegrity(1))
+
+		again, err := decoded.MarshalCBOR()
+		assert.Nil(t, err)
+		assert.Equal(t, data, again)
+	}
+}
+
+func TestTransaction_CBORDeterministic(t *testing.T) {
+	for _, tx := range mockCBORTransactions(t) {
+		first, err := tx.MarshalCBOR()
+		assert.Nil(t, err)
+		second, err := tx.Clone().MarshalCBOR()
+		assert.Nil(t, err)
+		assert.Equal(t, first, second)
+	}
+}
+
+func TestTransaction_CBORHead(t *testing.T) {
+	tests := []struct {
+		n    uint64
+		want []byte
+	}{
+		{0, []byte{0x00}},
+		{23, []byte{0x17}},
+		{24, []byte{0x18, 0x18}},
+		{255, []byte{0x18, 0xff}},
+		{256, []byte{0x19, 0x01, 0x00}},
+		{65536, []byte{0x1a, 0x00, 0x01, 0x00, 0x00}},
+		{1 << 32, []byte{0x1b, 0x00, 0x00, 0x00, 0x01, 0x00, 0x00, 0x00, 0x00}},
+	}
+	for _, tt := range tests {
+		e := new(cborEncoder)
+		e.head(cborUint, tt.n)
+		assert.Equal(t, tt.want, e.buf.Bytes())
+
+		d := &cborDecoder{data: tt.want}
+		n, err := d.expect(cborUint)
+		assert.Nil(t, err)
+		assert.Equal(t, tt.n, n)
+	}
+
+	e := new(cborEncoder)
+	e.intField(6, -1)
+	assert.Equal(t, []byte{0x06, 0x20}, e.buf.Bytes())
+}
+
+func TestTransaction_UnmarshalCBORRejects(t *testing.T) {
+	tx := mockCBORTransactions(t)[0]
+	data, err := tx.MarshalCBOR()
+	assert.Nil(t, err)
+
+	// 0xa? is the map head, the key of the first field is 0x01 (hash).
+	assert.Equal(t, byte(0x01), data[1])
+
+	nonShortest := append([]byte{data[0], 0x18, 0x01}, data[2:]...)
+	assert.Equal(t, ErrNonCanonicalSerialization, new(Transaction).UnmarshalCBOR(nonShortest))
+
+	trailing := append(append([]byte{}, data...), 0x00)
+	assert.Equal(t, ErrInvalidCBOR, new(Transaction).UnmarshalCBOR(trailing))
+
+	assert.Equal(t, ErrInvalidCBOR, new(Transaction).UnmarshalCBOR(data[:len(data)-1]))
+	assert.Equal(t, ErrInvalidCBOR, new(Transaction).UnmarshalCBOR(nil))
+
+	unknown := append([]byte{data[0] + 1}, data[1:]...)
+	unknown = append(unknown, 0x18, 0x63, 0x00)
+	assert.Equal(t, ErrInvalidCBOR, new(Transaction).UnmarshalCBOR(unknown))
+
+	indefinite := append([]byte{0xbf}, data[1:]...)
+	assert.Equal(t, ErrInvalidCBOR, new(Transaction).UnmarshalCBOR(indefinite))
+}
@@ -0,0 +1,84 @@
+// Copyright (C) 2017 go-nebulas authors
+//
+// This file is part of the go-nebulas library.
+//
+// the go-nebulas library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// the go-nebulas library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with the go-nebulas library.  If not, see <http://www.gnu.org/licenses/>.
+//
+
+package core
+
+import (
+	"encoding/json"
+
+	"github.com/alexlisong/go-nebulas/util"
+)
+
+// TxClass is the economic type of a tx shown by explorers.
+type TxClass string
+
+// Tx classes
+const (
+	TxClassUnknown       TxClass = "unknown"
+	TxClassTransfer      TxClass = "transfer"
+	TxClassDeploy        TxClass = "deploy"
+	TxClassCall          TxClass = "call"
+	TxClassTokenTransfer TxClass = "token_transfer"
+	TxClassCancel        TxClass = "cancel"
+	TxClassPing          TxClass = "ping"
+)
+
+// tokenTransferArgs is the count of args of the NRC20 token transfer functions
+var tokenTransferArgs = map[string]int{
+	"transfer":     2, // transfer(to, value)
+	"transferFrom": 3, // transferFrom(from, to, value)
+}
+
+// Classify return the economic type of the tx by its payload type, data, value, from and to.
+func (tx *Transaction) Classify() TxClass {
+	switch tx.Type() {
+	case TxPayloadBinaryType:
+		if tx.value.Cmp(util.NewUint128()) > 0 {
+			return TxClassTransfer
+		}
+		if tx.from.Equals(tx.to) && len(tx.Data()) == 0 {
+			return TxClassCancel
+		}
+		return TxClassPing
+	case TxPayloadDeployType:
+		return TxClassDeploy
+	case TxPayloadCallType:
+		payload, err := LoadCallPayload(tx.Data())
+		if err != nil {
+			return TxClassUnknown
+		}
+		if isTokenTransfer(payload) {
+			return TxClassTokenTransfer
+		}
+		return TxClassCall
+	default:
+		return TxClassUnknown
+	}
+}
+
+func isTokenTransfer(payload *CallPayload) bool {
+	count, ok := tokenTransferArgs[payload.Function]
+	if !ok {
+		return false
+	}
+	var args []interface{}
+	if err := json.Unmarshal([]byte(payload.Args), &args); err != nil {
+		return false
+	}
+	return len(args) == count
+}
@@ -0,0 +1,62 @@
+// Copyright (C) 2017 go-nebulas authors
+//
+// This file is part of the go-nebulas library.
+//
+// the go-nebulas library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// the go-nebulas library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with the go-nebulas library.  If not, see <http://www.gnu.org/licenses/>.
+//
+
+package core
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTransaction_Classify(t *testing.T) {
+	from := mockAddress()
+	call := func(function, args string) []byte {
+		payload, _ := NewCallPayload(function, args)
+		bytes, _ := payload.ToBytes()
+		return bytes
+	}
+	token := mockAddress()
+
+	tests := []struct {
+		name string
+		tx   *Transaction
+		want TxClass
+	}{
+		{"transfer", mockPayloadTransaction(from, mockAddress(), 100, TxPayloadBinaryType, nil), TxClassTransfer},
+		{"transfer to self", mockPayloadTransaction(from, from, 100, TxPayloadBinaryType, nil), TxClassTransfer},
+		{"cancel", mockPayloadTransaction(from, from, 0, TxPayloadBinaryType, nil), TxClassCancel},
+		{"ping", mockPayloadTransaction(from, mockAddress(), 0, TxPayloadBinaryType, nil), TxClassPing},
+		{"ping with data", mockPayloadTransaction(from, from, 0, TxPayloadBinaryType, []byte("hi")), TxClassPing},
+		{"deploy", mockDeployTransaction(1, 1), TxClassDeploy},
+		{"call", mockPayloadTransaction(from, token, 0, TxPayloadCallType, call("balanceOf", `["n1FF1nz6tarkDVwWQkMnnwFPuPKUaQTdptE"]`)), TxClassCall},
+		{"token transfer", mockPayloadTransaction(from, token, 0, TxPayloadCallType, call("transfer", `["n1FF1nz6tarkDVwWQkMnnwFPuPKUaQTdptE", "100"]`)), TxClassTokenTransfer},
+		{"token transferFrom", mockPayloadTransaction(from, token, 0, TxPayloadCallType, call("transferFrom", `["n1FF1nz6tarkDVwWQkMnnwFPuPKUaQTdptE", "n1GmkKH6nBMw4rrjt16RrJ9WcgvKUtAZP1s", "100"]`)), TxClassTokenTransfer},
+		{"transfer with wrong args", mockPayloadTransaction(from, token, 0, TxPayloadCallType, call("transfer", `["100"]`)), TxClassCall},
+		{"malformed call", mockPayloadTransaction(from, token, 0, TxPayloadCallType, []byte("{")), TxClassUnknown},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, tt.tx.Classify())
+		})
+	}
+
+	tx := mockNormalTransaction(1, 1)
+	tx.data.Type = "unknown"
+	assert.Equal(t, TxClassUnknown, tx.Classify())
+}
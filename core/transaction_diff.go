@@ -0,0 +1,62 @@
+// Copyright (C) 2017 go-nebulas authors
+//
+// This file is part of the go-nebulas library.
+//
+// the go-nebulas library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// the go-nebulas library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with the go-nebulas library.  If not, see <http://www.gnu.org/licenses/>.
+//
+
+package core
+
+import (
+	"sort"
+)
+
+// FieldChange is a signed field changed between two versions of a tx, values are in the form of CanonicalJSON,
+// "" when the field is not set.
+type FieldChange struct {
+	Field string
+	Old   string
+	New   string
+}
+
+// DiffPatch list the signed fields changed from tx to newer, sorted by field name,
+// newer must replace tx, with the same sender and nonce.
+func (tx *Transaction) DiffPatch(newer *Transaction) ([]FieldChange, error) {
+	if newer == nil {
+		return nil, ErrNilArgument
+	}
+	if !tx.from.Equals(newer.from) || tx.nonce != newer.nonce {
+		return nil, ErrNotReplacementTransaction
+	}
+
+	oldFields, newFields := tx.canonicalFields(), newer.canonicalFields()
+	names := make(map[string]bool)
+	for name := range oldFields {
+		names[name] = true
+	}
+	for name := range newFields {
+		names[name] = true
+	}
+
+	var changes []FieldChange
+	for name := range names {
+		if oldFields[name] != newFields[name] {
+			changes = append(changes, FieldChange{Field: name, Old: oldFields[name], New: newFields[name]})
+		}
+	}
+	sort.Slice(changes, func(i, j int) bool {
+		return changes[i].Field < changes[j].Field
+	})
+	return changes, nil
+}
@@ -0,0 +1,73 @@
+// Copyright (C) 2017 go-nebulas authors
+//
+// This file is part of the go-nebulas library.
+//
+// the go-nebulas library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// the go-nebulas library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with the go-nebulas library.  If not, see <http://www.gnu.org/licenses/>.
+//
+
+package core
+
+import (
+	"testing"
+
+	"github.com/alexlisong/go-nebulas/util"
+	"github.com/alexlisong/go-nebulas/util/byteutils"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTransaction_DiffPatch(t *testing.T) {
+	original := mockNormalTransaction(1, 1)
+	assert.Nil(t, signTx(original))
+
+	same := original.Clone()
+	changes, err := original.DiffPatch(same)
+	assert.Nil(t, err)
+	assert.Nil(t, changes)
+
+	// fee bump
+	bumped := original.Clone()
+	bumped.gasPrice, _ = original.gasPrice.Mul(util.NewUint128FromUint(2))
+	bumped.gasLimit = util.NewUint128FromUint(300000)
+	assert.Nil(t, signTx(bumped))
+	changes, err = original.DiffPatch(bumped)
+	assert.Nil(t, err)
+	assert.Equal(t, []FieldChange{
+		{"gasLimit", original.gasLimit.String(), "300000"},
+		{"gasPrice", original.gasPrice.String(), bumped.gasPrice.String()},
+	}, changes)
+
+	// data change and a field set only in the newer tx
+	edited := original.Clone()
+	edited.Thaw()
+	edited.data.Payload = []byte("memo")
+	assert.Nil(t, edited.SetTipRecipient(mockAddress()))
+	changes, err = original.DiffPatch(edited)
+	assert.Nil(t, err)
+	assert.Equal(t, []FieldChange{
+		{"data", "", byteutils.Hex([]byte("memo"))},
+		{"tipRecipient", "", edited.tipRecipient.String()},
+	}, changes)
+
+	// not the same intent
+	other := original.Clone()
+	other.nonce = 2
+	_, err = original.DiffPatch(other)
+	assert.Equal(t, ErrNotReplacementTransaction, err)
+	other = original.Clone()
+	other.from = mockAddress()
+	_, err = original.DiffPatch(other)
+	assert.Equal(t, ErrNotReplacementTransaction, err)
+	_, err = original.DiffPatch(nil)
+	assert.Equal(t, ErrNilArgument, err)
+}
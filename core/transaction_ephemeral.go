@@ -0,0 +1,141 @@
+// Copyright (C) 2017 go-nebulas authors
+//
+// This file is part of the go-nebulas library.
+//
+// the go-nebulas library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// the go-nebulas library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with the go-nebulas library.  If not, see <http://www.gnu.org/licenses/>.
+//
+
+package core
+
+import (
+	"github.com/alexlisong/go-nebulas/crypto"
+	"github.com/alexlisong/go-nebulas/crypto/keystore"
+	"github.com/alexlisong/go-nebulas/crypto/keystore/secp256k1"
+)
+
+// uncompressedPubKeyPrefix is the first byte of an uncompressed secp256k1 public key
+const uncompressedPubKeyPrefix = 0x04
+
+// EphemeralPubKey return the attached public key of the one-time signing key, nil if not signed with one.
+func (tx *Transaction) EphemeralPubKey() []byte {
+	return tx.ephemeralPubKey
+}
+
+// SignerPublicKey return the public key of the signer, it's the attached ephemeral public key if present,
+// otherwise it's recovered from the sign and hash of the tx.
+func (tx *Transaction) SignerPublicKey() ([]byte, error) {
+	if len(tx.ephemeralPubKey) > 0 {
+		pub := make([]byte, len(tx.ephemeralPubKey))
+		copy(pub, tx.ephemeralPubKey)
+		return pub, nil
+	}
+	signature, err := crypto.NewSignature(tx.alg)
+	if err != nil {
+		return nil, err
+	}
+	pub, err := signature.RecoverPublic(tx.hash, tx.sign)
+	if err != nil {
+		return nil, err
+	}
+	return pub.Encoded()
+}
+
+// SignWithEphemeralKey sign the tx with a one-time key and attach its public key,
+// tx.from must be the address of the key.
+func (tx *Transaction) SignWithEphemeralKey(key keystore.PrivateKey) error {
+	if key == nil {
+		return ErrNilArgument
+	}
+	// the public key is hashed, so it can't be attached to a signed tx.
+	if tx.frozen {
+		return ErrTransactionFrozen
+	}
+	pub, err := key.PublicKey().Encoded()
+	if err != nil {
+		return err
+	}
+	if err := checkEphemeralPubKey(pub); err != nil {
+		return err
+	}
+	addr, err := NewAddressFromPublicKey(pub)
+	if err != nil {
+		return err
+	}
+	if !tx.from.Equals(addr) {
+		return ErrInvalidTransactionSigner
+	}
+
+	// public key is hashed, so attach it before signing.
+	tx.ephemeralPubKey = pub
+	tx.cachedHash = nil
+	signature, err := crypto.NewSignature(key.Algorithm())
+	if err != nil {
+		tx.ephemeralPubKey, tx.cachedHash = nil, nil
+		return err
+	}
+	if err := signature.InitSign(key); err != nil {
+		tx.ephemeralPubKey, tx.cachedHash = nil, nil
+		return err
+	}
+	if err := tx.Sign(signature); err != nil {
+		tx.ephemeralPubKey, tx.cachedHash = nil, nil
+		return err
+	}
+	return nil
+}
+
+// verifyEphemeralSign verify the sign with the attached public key instead of recovering it.
+func (tx *Transaction) verifyEphemeralSign() error {
+	if err := checkEphemeralPubKey(tx.ephemeralPubKey); err != nil {
+		return err
+	}
+	addr, err := NewAddressFromPublicKey(tx.ephemeralPubKey)
+	if err != nil {
+		return err
+	}
+	if !tx.from.Equals(addr) {
+		return ErrInvalidTransactionSigner
+	}
+	if len(tx.sign) != secp256k1.RecoverableSignatureLength {
+		return ErrInvalidSignature
+	}
+
+	signature, err := crypto.NewSignature(tx.alg)
+	if err != nil {
+		return err
+	}
+	if err := signature.InitVerify(secp256k1.NewPublicKey(tx.ephemeralPubKey)); err != nil {
+		return err
+	}
+	ok, err := signature.Verify(tx.hash, tx.sign)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return ErrInvalidSignature
+	}
+	return nil
+}
+
+// checkEphemeralPubKey check pub is an uncompressed secp256k1 public key on the curve.
+func checkEphemeralPubKey(pub []byte) error {
+	if len(pub) != PublicKeyDataLength || pub[0] != uncompressedPubKeyPrefix {
+		return ErrInvalidEphemeralPubKey
+	}
+	ecdsaPub, err := secp256k1.ToECDSAPublicKey(pub)
+	if err != nil || ecdsaPub.X == nil {
+		return ErrInvalidEphemeralPubKey
+	}
+	return nil
+}
@@ -0,0 +1,103 @@
+// Copyright (C) 2017 go-nebulas authors
+//
+// This file is part of the go-nebulas library.
+//
+// the go-nebulas library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// the go-nebulas library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with the go-nebulas library.  If not, see <http://www.gnu.org/licenses/>.
+//
+
+package core
+
+import (
+	"testing"
+
+	"github.com/alexlisong/go-nebulas/core/pb"
+	"github.com/alexlisong/go-nebulas/crypto"
+	"github.com/alexlisong/go-nebulas/crypto/keystore"
+	"github.com/alexlisong/go-nebulas/crypto/keystore/secp256k1"
+	"github.com/gogo/protobuf/proto"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTransaction_SignWithEphemeralKey(t *testing.T) {
+	key := secp256k1.GeneratePrivateKey()
+	pub, _ := key.PublicKey().Encoded()
+	from, _ := NewAddressFromPublicKey(pub)
+
+	tx := mockNormalTransaction(1, 1)
+	assert.Equal(t, ErrInvalidTransactionSigner, tx.SignWithEphemeralKey(key))
+	assert.Nil(t, tx.EphemeralPubKey())
+
+	tx.from = from
+	unsignedHash, _ := tx.calHash()
+	assert.Nil(t, tx.SignWithEphemeralKey(key))
+	assert.Equal(t, pub, tx.EphemeralPubKey())
+	assert.NotEqual(t, unsignedHash, tx.hash)
+	assert.Nil(t, tx.VerifyIntegrity(1))
+
+	pbTx, err := tx.ToProto()
+	assert.Nil(t, err)
+	ir, _ := proto.Marshal(pbTx)
+	msg := new(corepb.Transaction)
+	assert.Nil(t, proto.Unmarshal(ir, msg))
+	decoded := new(Transaction)
+	assert.Nil(t, decoded.FromProto(msg))
+	assert.Equal(t, pub, decoded.EphemeralPubKey())
+	assert.Nil(t, decoded.VerifyIntegrity(1))
+	size, _ := decoded.Size()
+	assert.Equal(t, size, decoded.EstimatedSize())
+
+	// sign by another key does not verify with the attached key.
+	other := mockNormalTransaction(1, 1)
+	other.from = from
+	other.ephemeralPubKey = pub
+	signature, _ := crypto.NewSignature(keystore.SECP256K1)
+	signature.InitSign(secp256k1.GeneratePrivateKey())
+	assert.Nil(t, other.Sign(signature))
+	assert.Equal(t, ErrInvalidSignature, other.VerifyIntegrity(1))
+
+	// malformed public keys
+	msg.EphemeralPubKey = pub[1:]
+	assert.Equal(t, ErrInvalidEphemeralPubKey, new(Transaction).FromProto(msg))
+	offCurve := make([]byte, PublicKeyDataLength)
+	offCurve[0] = 0x04
+	offCurve[64] = 0x01
+	msg.EphemeralPubKey = offCurve
+	assert.Equal(t, ErrInvalidEphemeralPubKey, new(Transaction).FromProto(msg))
+}
+
+func TestTransaction_SignerPublicKey(t *testing.T) {
+	key := secp256k1.GeneratePrivateKey()
+	pub, _ := key.PublicKey().Encoded()
+	from, _ := NewAddressFromPublicKey(pub)
+
+	attached := mockNormalTransaction(1, 1)
+	attached.from = from
+	assert.Nil(t, attached.SignWithEphemeralKey(key))
+	signer, err := attached.SignerPublicKey()
+	assert.Nil(t, err)
+	assert.Equal(t, pub, signer)
+
+	recovered := mockNormalTransaction(1, 1)
+	assert.Nil(t, signTx(recovered))
+	assert.Nil(t, recovered.EphemeralPubKey())
+	signer, err = recovered.SignerPublicKey()
+	assert.Nil(t, err)
+	addr, err := NewAddressFromPublicKey(signer)
+	assert.Nil(t, err)
+	assert.True(t, recovered.from.Equals(addr))
+
+	unsigned := mockNormalTransaction(1, 1)
+	_, err = unsigned.SignerPublicKey()
+	assert.NotNil(t, err)
+}
@@ -0,0 +1,135 @@
+// Copyright (C) 2017 go-nebulas authors
+//
+// This file is part of the go-nebulas library.
+//
+// the go-nebulas library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// the go-nebulas library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with the go-nebulas library.  If not, see <http://www.gnu.org/licenses/>.
+//
+
+package core
+
+import (
+	"github.com/alexlisong/go-nebulas/util/byteutils"
+	"github.com/gogo/protobuf/proto"
+)
+
+const (
+	// FieldElementSize is the byte size of each element returned by ToFieldElements.
+	FieldElementSize = 32
+	// FieldElementLimbSize is the byte size of the data carried by an element, 248 bits fit in
+	// the 254 bits scalar field of BN254 without reduction.
+	FieldElementLimbSize = 31
+)
+
+// ToFieldElements encode tx as field elements for zk circuits.
+// Each element is a FieldElementSize bytes big-endian integer lower than 2^248.
+// The fields are encoded in calHash order, each as one element of its byte length,
+// followed by ceil(length / FieldElementLimbSize) limbs of its bytes, the last limb carries
+// the remainder in its low bytes:
+//
+//	from, to                    26 bytes address
+//	value                       16 bytes big-endian
+//	nonce, timestamp            8 bytes big-endian
+//	data                        proto bytes of the payload
+//	chainID                     4 bytes big-endian
+//	gasPrice, gasLimit          16 bytes big-endian
+//	salt, tipRecipient,
+//	ephemeralPubKey, powNonce,
+//	validFrom, deadline,
+//	forkMarker                  same as above, 0 length when not set
+//
+// The tx hash domain and the signature are not encoded.
+func (tx *Transaction) ToFieldElements() ([][]byte, error) {
+	fields, err := tx.fieldBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	var elements [][]byte
+	for _, field := range fields {
+		elements = appendFieldElements(elements, field)
+	}
+	return elements, nil
+}
+
+// appendFieldElements append the length element and the limbs of field to elements.
+func appendFieldElements(elements [][]byte, field []byte) [][]byte {
+	elements = append(elements, toFieldElement(byteutils.FromUint64(uint64(len(field)))))
+	for start := 0; start < len(field); start += FieldElementLimbSize {
+		end := start + FieldElementLimbSize
+		if end > len(field) {
+			end = len(field)
+		}
+		elements = append(elements, toFieldElement(field[start:end]))
+	}
+	return elements
+}
+
+// fieldBytes return the bytes of each field encoded by ToFieldElements in order.
+func (tx *Transaction) fieldBytes() ([][]byte, error) {
+	value, err := tx.value.ToFixedSizeByteSlice()
+	if err != nil {
+		return nil, err
+	}
+	data, err := proto.Marshal(tx.data)
+	if err != nil {
+		return nil, err
+	}
+	gasPrice, err := tx.gasPrice.ToFixedSizeByteSlice()
+	if err != nil {
+		return nil, err
+	}
+	gasLimit, err := tx.gasLimit.ToFixedSizeByteSlice()
+	if err != nil {
+		return nil, err
+	}
+
+	var tipRecipient, powNonce, validFrom, deadline []byte
+	if tx.tipRecipient != nil {
+		tipRecipient = tx.tipRecipient.address
+	}
+	if tx.powNonce != 0 {
+		powNonce = byteutils.FromUint64(tx.powNonce)
+	}
+	if tx.validFrom != 0 {
+		validFrom = byteutils.FromInt64(tx.validFrom)
+	}
+	if tx.deadline != 0 {
+		deadline = byteutils.FromInt64(tx.deadline)
+	}
+	return [][]byte{
+		tx.from.address,
+		tx.to.address,
+		value,
+		byteutils.FromUint64(tx.nonce),
+		byteutils.FromInt64(tx.timestamp),
+		data,
+		byteutils.FromUint32(tx.chainID),
+		gasPrice,
+		gasLimit,
+		tx.salt,
+		tipRecipient,
+		tx.ephemeralPubKey,
+		powNonce,
+		validFrom,
+		deadline,
+		tx.forkMarker,
+	}, nil
+}
+
+// toFieldElement right align limb in a FieldElementSize bytes element.
+func toFieldElement(limb []byte) []byte {
+	element := make([]byte, FieldElementSize)
+	copy(element[FieldElementSize-len(limb):], limb)
+	return element
+}
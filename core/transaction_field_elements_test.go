@@ -0,0 +1,108 @@
+// Copyright (C) 2017 go-nebulas authors
+//
+// This file is part of the go-nebulas library.
+//
+// the go-nebulas library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// the go-nebulas library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with the go-nebulas library.  If not, see <http://www.gnu.org/licenses/>.
+//
+
+package core
+
+import (
+	"testing"
+
+	"github.com/alexlisong/go-nebulas/core/pb"
+	"github.com/alexlisong/go-nebulas/util"
+	"github.com/alexlisong/go-nebulas/util/byteutils"
+	"github.com/gogo/protobuf/proto"
+	"github.com/stretchr/testify/assert"
+)
+
+// fromFieldElements decode the fields as laid out in ToFieldElements.
+func fromFieldElements(elements [][]byte) [][]byte {
+	var fields [][]byte
+	for i := 0; i < len(elements); {
+		length := byteutils.Uint64(elements[i][FieldElementSize-8:])
+		i++
+		field := []byte{}
+		for left := int(length); left > 0; left -= FieldElementLimbSize {
+			size := FieldElementLimbSize
+			if left < size {
+				size = left
+			}
+			field = append(field, elements[i][FieldElementSize-size:]...)
+			i++
+		}
+		fields = append(fields, field)
+	}
+	return fields
+}
+
+func TestTransaction_ToFieldElements(t *testing.T) {
+	tx := mockCallTransaction(1, 3, "transfer", "[\"n1FkntVUMPAsESuCAAPK711omQk19JotBjM\", \"1000000\"]")
+	tx.value, _ = util.NewUint128FromInt(123456789)
+	tx.SetTipRecipient(mockAddress())
+	assert.Nil(t, tx.SetReplaySalt(mockReplaySalt()))
+	assert.Nil(t, tx.AttachPoW(4))
+
+	elements, err := tx.ToFieldElements()
+	assert.Nil(t, err)
+	for _, element := range elements {
+		assert.Equal(t, FieldElementSize, len(element))
+		assert.Equal(t, byte(0), element[0], "element must be lower than 2^248")
+	}
+
+	fields := fromFieldElements(elements)
+	assert.Equal(t, 16, len(fields))
+	assert.Equal(t, tx.from.Bytes(), fields[0])
+	assert.Equal(t, tx.to.Bytes(), fields[1])
+	value, err := util.NewUint128FromFixedSizeByteSlice(fields[2])
+	assert.Nil(t, err)
+	assert.Equal(t, tx.value, value)
+	assert.Equal(t, tx.nonce, byteutils.Uint64(fields[3]))
+	assert.Equal(t, tx.timestamp, byteutils.Int64(fields[4]))
+	data := new(corepb.Data)
+	assert.Nil(t, proto.Unmarshal(fields[5], data))
+	assert.Equal(t, tx.data.Type, data.Type)
+	assert.Equal(t, tx.data.Payload, data.Payload)
+	assert.Equal(t, tx.chainID, byteutils.Uint32(fields[6]))
+	gasPrice, _ := util.NewUint128FromFixedSizeByteSlice(fields[7])
+	assert.Equal(t, tx.gasPrice, gasPrice)
+	gasLimit, _ := util.NewUint128FromFixedSizeByteSlice(fields[8])
+	assert.Equal(t, tx.gasLimit, gasLimit)
+	assert.Equal(t, tx.salt, fields[9])
+	assert.Equal(t, tx.tipRecipient.Bytes(), fields[10])
+	assert.Equal(t, []byte{}, fields[11])
+	assert.Equal(t, tx.powNonce, byteutils.Uint64(fields[12]))
+
+	// the payload spans several limbs
+	assert.True(t, len(fields[5]) > FieldElementLimbSize)
+}
+
+func TestTransaction_ToFieldElementsUnsetFields(t *testing.T) {
+	tx := mockNormalTransaction(1, 1)
+	elements, err := tx.ToFieldElements()
+	assert.Nil(t, err)
+
+	fields := fromFieldElements(elements)
+	assert.Equal(t, 16, len(fields))
+	for i := 9; i < 16; i++ {
+		assert.Equal(t, []byte{}, fields[i])
+	}
+
+	// an optional field once set add its limbs after its length element
+	tx.SetTipRecipient(mockAddress())
+	withTip, err := tx.ToFieldElements()
+	assert.Nil(t, err)
+	assert.Equal(t, len(elements)+1, len(withTip))
+}
@@ -0,0 +1,66 @@
+// Copyright (C) 2017 go-nebulas authors
+//
+// This file is part of the go-nebulas library.
+//
+// the go-nebulas library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// the go-nebulas library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with the go-nebulas library.  If not, see <http://www.gnu.org/licenses/>.
+//
+
+package core
+
+import (
+	"bytes"
+)
+
+// forkID is the id of the fork the node follows after a chain split, empty before any split
+var forkID []byte
+
+// SetForkID set the fork id of the node, nodes of each fork set it at init,
+// txs marked for another fork are rejected.
+func SetForkID(id []byte) {
+	forkID = make([]byte, len(id))
+	copy(forkID, id)
+}
+
+// ForkID return the fork id of the node
+func ForkID() []byte {
+	return forkID
+}
+
+// ForkMarker return the id of the fork the tx is intended for, nil if valid on any fork
+func (tx *Transaction) ForkMarker() []byte {
+	return tx.forkMarker
+}
+
+// SetForkMarker set the id of the fork the tx is intended for, it is hashed so must be set before signing
+func (tx *Transaction) SetForkMarker(marker []byte) error {
+	if tx.frozen {
+		return ErrTransactionFrozen
+	}
+	tx.forkMarker = nil
+	if len(marker) > 0 {
+		tx.forkMarker = make([]byte, len(marker))
+		copy(tx.forkMarker, marker)
+	}
+	tx.rawBytes = nil
+	tx.cachedHash = nil
+	return nil
+}
+
+// checkForkMarker check a marked tx is intended for the fork of the node, txs without marker pass.
+func (tx *Transaction) checkForkMarker() error {
+	if len(tx.forkMarker) > 0 && !bytes.Equal(tx.forkMarker, forkID) {
+		return ErrWrongFork
+	}
+	return nil
+}
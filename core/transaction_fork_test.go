@@ -0,0 +1,77 @@
+// Copyright (C) 2017 go-nebulas authors
+//
+// This file is part of the go-nebulas library.
+//
+// the go-nebulas library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// the go-nebulas library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with the go-nebulas library.  If not, see <http://www.gnu.org/licenses/>.
+//
+
+package core
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTransaction_ForkMarker(t *testing.T) {
+	defer SetForkID(nil)
+
+	unmarked := mockNormalTransaction(1, 1)
+	assert.Nil(t, signTx(unmarked))
+	marked := mockNormalTransaction(1, 2)
+	marked.SetForkMarker([]byte("fork-a"))
+	assert.Equal(t, []byte("fork-a"), marked.ForkMarker())
+	assert.Equal(t, TxHashV2, marked.HashVersion())
+	assert.Nil(t, signTx(marked))
+
+	// the marker is hashed.
+	other := marked.Clone()
+	other.Thaw()
+	assert.Nil(t, other.SetForkMarker([]byte("fork-b")))
+	otherHash, err := other.calHash()
+	assert.Nil(t, err)
+	assert.NotEqual(t, marked.hash, otherHash)
+
+	tests := []struct {
+		name     string
+		forkID   []byte
+		unmarked error
+		marked   error
+	}{
+		{"no fork id", nil, nil, ErrWrongFork},
+		{"matching fork", []byte("fork-a"), nil, nil},
+		{"other fork", []byte("fork-b"), nil, ErrWrongFork},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			SetForkID(tt.forkID)
+			assert.Equal(t, tt.unmarked, unmarked.VerifyIntegrity(1))
+			assert.Equal(t, tt.marked, marked.VerifyIntegrity(1))
+			assert.Equal(t, tt.marked, marked.VerifyWithHash(marked.hash, 1))
+		})
+	}
+
+	// the marker survives serialization.
+	SetForkID([]byte("fork-a"))
+	msg, err := marked.ToProto()
+	assert.Nil(t, err)
+	decoded := new(Transaction)
+	assert.Nil(t, decoded.FromProto(msg))
+	assert.True(t, marked.Equals(decoded))
+	assert.Nil(t, decoded.VerifyIntegrity(1))
+
+	marked.Thaw()
+	assert.Nil(t, marked.SetForkMarker(nil))
+	assert.Nil(t, marked.ForkMarker())
+}
@@ -0,0 +1,31 @@
+// Copyright (C) 2017 go-nebulas authors
+//
+// This file is part of the go-nebulas library.
+//
+// the go-nebulas library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// the go-nebulas library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with the go-nebulas library.  If not, see <http://www.gnu.org/licenses/>.
+//
+
+package core
+
+// IsFrozen check if the tx is frozen by signing, setters of hashed fields return ErrTransactionFrozen then.
+func (tx *Transaction) IsFrozen() bool {
+	return tx.frozen
+}
+
+// Thaw clear the signature of a signed tx so its hashed fields can be set again, the tx must be signed after.
+func (tx *Transaction) Thaw() {
+	tx.hash, tx.alg, tx.sign = nil, 0, nil
+	tx.rawBytes = nil
+	tx.frozen = false
+}
@@ -0,0 +1,65 @@
+// Copyright (C) 2017 go-nebulas authors
+//
+// This file is part of the go-nebulas library.
+//
+// the go-nebulas library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// the go-nebulas library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with the go-nebulas library.  If not, see <http://www.gnu.org/licenses/>.
+//
+
+package core
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTransaction_Freeze(t *testing.T) {
+	SetForkID([]byte("fork-a"))
+	defer SetForkID(nil)
+
+	tx := mockNormalTransaction(1, 1)
+	assert.False(t, tx.IsFrozen())
+	assert.Nil(t, tx.SetTipRecipient(mockAddress()))
+	assert.Nil(t, signTx(tx))
+	assert.True(t, tx.IsFrozen())
+	signed, err := tx.ToProto()
+	assert.Nil(t, err)
+
+	// a frozen tx rejects mutation and stays valid.
+	assert.Equal(t, ErrTransactionFrozen, tx.SetTipRecipient(mockAddress()))
+	assert.Equal(t, ErrTransactionFrozen, tx.SetForkMarker([]byte("fork-a")))
+	assert.Equal(t, ErrTransactionFrozen, tx.SetReplaySalt(mockReplaySalt()))
+	assert.Equal(t, ErrTransactionFrozen, tx.SetValidityWindow(time.Unix(1500000000, 0), time.Time{}))
+	assert.Equal(t, ErrTransactionFrozen, tx.SetHashAlgorithm(TxHashPoseidon))
+	assert.Equal(t, ErrTransactionFrozen, tx.AttachPoW(1))
+	assert.Nil(t, tx.VerifyIntegrity(1))
+	msg, err := tx.ToProto()
+	assert.Nil(t, err)
+	assert.Equal(t, signed, msg)
+
+	// clones of a frozen tx are frozen too.
+	assert.True(t, tx.Clone().IsFrozen())
+
+	// a thawed tx drops its signature and allows mutation.
+	tx.Thaw()
+	assert.False(t, tx.IsFrozen())
+	assert.Nil(t, tx.hash)
+	assert.Nil(t, tx.sign)
+	assert.Nil(t, tx.SetForkMarker([]byte("fork-a")))
+	assert.Equal(t, []byte("fork-a"), tx.ForkMarker())
+	assert.Nil(t, signTx(tx))
+	assert.True(t, tx.IsFrozen())
+	assert.Nil(t, tx.VerifyIntegrity(1))
+}
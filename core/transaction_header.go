@@ -0,0 +1,204 @@
+// Copyright (C) 2017 go-nebulas authors
+//
+// This file is part of the go-nebulas library.
+//
+// the go-nebulas library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// the go-nebulas library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with the go-nebulas library.  If not, see <http://www.gnu.org/licenses/>.
+//
+
+package core
+
+import (
+	"encoding/binary"
+	"io"
+	"io/ioutil"
+
+	"github.com/gogo/protobuf/proto"
+	"github.com/alexlisong/go-nebulas/core/pb"
+	"github.com/alexlisong/go-nebulas/crypto/keystore"
+	"github.com/alexlisong/go-nebulas/util"
+	"github.com/alexlisong/go-nebulas/util/byteutils"
+)
+
+// TxHeader is the lightweight fields of a serialized tx, data and sign are not decoded.
+type TxHeader struct {
+	Hash      byteutils.Hash
+	From      *Address
+	To        *Address
+	Value     *util.Uint128
+	Nonce     uint64
+	Timestamp int64
+	ChainID   uint32
+	GasPrice  *util.Uint128
+	GasLimit  *util.Uint128
+	Alg       keystore.Algorithm
+}
+
+// proto wire types
+const (
+	wireVarint  = 0
+	wireFixed64 = 1
+	wireBytes   = 2
+	wireFixed32 = 5
+)
+
+// field numbers of corepb.Transaction
+const (
+	txFieldHash      = 1
+	txFieldFrom      = 2
+	txFieldTo        = 3
+	txFieldValue     = 4
+	txFieldNonce     = 5
+	txFieldTimestamp = 6
+	txFieldChainID   = 8
+	txFieldGasPrice  = 9
+	txFieldGasLimit  = 10
+	txFieldAlg       = 11
+)
+
+// ReadTransactionHeader read a proto serialized tx from r and decode only the header fields,
+// data, sign and other fields are skipped without copying.
+func ReadTransactionHeader(r io.Reader) (*TxHeader, error) {
+	ir, err := ioutil.ReadAll(io.LimitReader(r, int64(MaxBytesPerTransaction)+1))
+	if err != nil {
+		return nil, err
+	}
+	if uint64(len(ir)) > MaxBytesPerTransaction {
+		return nil, ErrTransactionTooLarge
+	}
+
+	header := &TxHeader{
+		Value:    util.NewUint128(),
+		GasPrice: util.NewUint128(),
+		GasLimit: util.NewUint128(),
+	}
+	for len(ir) > 0 {
+		key, n := binary.Uvarint(ir)
+		if n <= 0 {
+			return nil, ErrInvalidProtoToTransaction
+		}
+		ir = ir[n:]
+
+		var (
+			varint uint64
+			field  []byte
+		)
+		switch key & 0x7 {
+		case wireVarint:
+			varint, n = binary.Uvarint(ir)
+			if n <= 0 {
+				return nil, ErrInvalidProtoToTransaction
+			}
+		case wireFixed64:
+			n = 8
+		case wireFixed32:
+			n = 4
+		case wireBytes:
+			length, m := binary.Uvarint(ir)
+			if m <= 0 || length > uint64(len(ir)-m) {
+				return nil, ErrInvalidProtoToTransaction
+			}
+			field = ir[m : m+int(length)]
+			n = m + int(length)
+		default:
+			return nil, ErrInvalidProtoToTransaction
+		}
+		if n > len(ir) {
+			return nil, ErrInvalidProtoToTransaction
+		}
+		ir = ir[n:]
+
+		if err := header.setField(key>>3, varint, field); err != nil {
+			return nil, err
+		}
+	}
+	if header.From == nil || header.To == nil {
+		return nil, ErrInvalidProtoToTransaction
+	}
+	return header, nil
+}
+
+func (h *TxHeader) setField(number uint64, varint uint64, field []byte) error {
+	var err error
+	switch number {
+	case txFieldHash:
+		h.Hash = append(byteutils.Hash{}, field...)
+	case txFieldFrom:
+		h.From, err = AddressParseFromBytes(append([]byte{}, field...))
+	case txFieldTo:
+		h.To, err = AddressParseFromBytes(append([]byte{}, field...))
+	case txFieldValue:
+		h.Value, err = util.NewUint128FromFixedSizeByteSlice(field)
+	case txFieldNonce:
+		h.Nonce = varint
+	case txFieldTimestamp:
+		h.Timestamp = int64(varint)
+	case txFieldChainID:
+		h.ChainID = uint32(varint)
+	case txFieldGasPrice:
+		h.GasPrice, err = util.NewUint128FromFixedSizeByteSlice(field)
+	case txFieldGasLimit:
+		h.GasLimit, err = util.NewUint128FromFixedSizeByteSlice(field)
+	case txFieldAlg:
+		h.Alg = keystore.Algorithm(varint)
+	}
+	return err
+}
+
+// AssembleTransaction rebuild the tx from its header, the proto serialized data and sign kept in separate stores,
+// the rebuilt tx must have the hash recorded in header, the sign is not verified.
+// txs with fields not in the header, like salt, can't be rebuilt.
+func AssembleTransaction(header *TxHeader, data []byte, sig []byte) (*Transaction, error) {
+	if header == nil || header.From == nil || header.To == nil ||
+		header.Value == nil || header.GasPrice == nil || header.GasLimit == nil {
+		return nil, ErrNilArgument
+	}
+	pbData := new(corepb.Data)
+	if err := proto.Unmarshal(data, pbData); err != nil {
+		return nil, err
+	}
+	value, err := header.Value.ToFixedSizeByteSlice()
+	if err != nil {
+		return nil, err
+	}
+	gasPrice, err := header.GasPrice.ToFixedSizeByteSlice()
+	if err != nil {
+		return nil, err
+	}
+	gasLimit, err := header.GasLimit.ToFixedSizeByteSlice()
+	if err != nil {
+		return nil, err
+	}
+
+	tx := new(Transaction)
+	if err := tx.FromProto(&corepb.Transaction{
+		Hash:      header.Hash,
+		From:      header.From.address,
+		To:        header.To.address,
+		Value:     value,
+		Nonce:     header.Nonce,
+		Timestamp: header.Timestamp,
+		Data:      pbData,
+		ChainId:   header.ChainID,
+		GasPrice:  gasPrice,
+		GasLimit:  gasLimit,
+		Alg:       uint32(header.Alg),
+		Sign:      sig,
+	}); err != nil {
+		return nil, err
+	}
+	if err := tx.ValidateStoredHash(); err != nil {
+		return nil, err
+	}
+	return tx, nil
+}
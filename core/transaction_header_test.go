@@ -0,0 +1,107 @@
+// Copyright (C) 2017 go-nebulas authors
+//
+// This file is part of the go-nebulas library.
+//
+// the go-nebulas library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// the go-nebulas library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with the go-nebulas library.  If not, see <http://www.gnu.org/licenses/>.
+//
+
+package core
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/gogo/protobuf/proto"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReadTransactionHeader(t *testing.T) {
+	deploy := mockDeployTransaction(1, 7)
+	assert.Nil(t, signTx(deploy))
+	tipped := mockNormalTransaction(1, 8)
+	tipped.SetTipRecipient(mockAddress())
+	assert.Nil(t, signTx(tipped))
+
+	txs := Transactions{mockSignedTransactions(t, 1)[0], deploy, tipped}
+	for _, tx := range txs {
+		pbTx, err := tx.ToProto()
+		assert.Nil(t, err)
+		ir, err := proto.Marshal(pbTx)
+		assert.Nil(t, err)
+
+		header, err := ReadTransactionHeader(bytes.NewReader(ir))
+		assert.Nil(t, err)
+		full := new(Transaction)
+		assert.Nil(t, full.FromProto(pbTx))
+
+		assert.Equal(t, full.hash, header.Hash)
+		assert.True(t, full.from.Equals(header.From))
+		assert.True(t, full.to.Equals(header.To))
+		assert.Equal(t, full.value, header.Value)
+		assert.Equal(t, full.nonce, header.Nonce)
+		assert.Equal(t, full.timestamp, header.Timestamp)
+		assert.Equal(t, full.chainID, header.ChainID)
+		assert.Equal(t, full.gasPrice, header.GasPrice)
+		assert.Equal(t, full.gasLimit, header.GasLimit)
+		assert.Equal(t, full.alg, header.Alg)
+
+		// truncated stream
+		_, err = ReadTransactionHeader(bytes.NewReader(ir[:len(ir)-1]))
+		assert.Equal(t, ErrInvalidProtoToTransaction, err)
+	}
+
+	_, err := ReadTransactionHeader(bytes.NewReader(nil))
+	assert.Equal(t, ErrInvalidProtoToTransaction, err)
+
+	_, err = ReadTransactionHeader(bytes.NewReader(make([]byte, MaxBytesPerTransaction+1)))
+	assert.Equal(t, ErrTransactionTooLarge, err)
+}
+
+func TestAssembleTransaction(t *testing.T) {
+	tx := mockCallTransaction(1, 3, "transfer", "[\"n1FkntVUMPAsESuCAAPK711omQk19JotBjM\", \"1\"]")
+	assert.Nil(t, signTx(tx))
+	ir, err := tx.Marshal()
+	assert.Nil(t, err)
+
+	// header, data and sign are stored apart
+	header, err := ReadTransactionHeader(bytes.NewReader(ir))
+	assert.Nil(t, err)
+	data, err := proto.Marshal(tx.data)
+	assert.Nil(t, err)
+	sig := []byte(tx.sign)
+
+	assembled, err := AssembleTransaction(header, data, sig)
+	assert.Nil(t, err)
+	assert.True(t, tx.Equals(assembled))
+	assert.Nil(t, assembled.VerifyIntegrity(1))
+
+	// data of another tx
+	other := mockDeployTransaction(1, 3)
+	otherData, err := proto.Marshal(other.data)
+	assert.Nil(t, err)
+	_, err = AssembleTransaction(header, otherData, sig)
+	assert.Equal(t, ErrHashMismatch, err)
+
+	// the sign is only checked by VerifyIntegrity
+	badSig := append([]byte{}, sig...)
+	badSig[0] ^= 0xff
+	assembled, err = AssembleTransaction(header, data, badSig)
+	assert.Nil(t, err)
+	assert.NotNil(t, assembled.VerifyIntegrity(1))
+
+	_, err = AssembleTransaction(header, []byte{0xff}, sig)
+	assert.NotNil(t, err)
+	_, err = AssembleTransaction(&TxHeader{}, data, sig)
+	assert.Equal(t, ErrNilArgument, err)
+}
@@ -0,0 +1,229 @@
+// Copyright (C) 2017 go-nebulas authors
+//
+// This file is part of the go-nebulas library.
+//
+// the go-nebulas library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// the go-nebulas library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with the go-nebulas library.  If not, see <http://www.gnu.org/licenses/>.
+//
+
+package core
+
+import (
+	"encoding/json"
+	"strconv"
+	"time"
+
+	"github.com/alexlisong/go-nebulas/core/pb"
+	"github.com/alexlisong/go-nebulas/crypto/keystore"
+	"github.com/alexlisong/go-nebulas/util"
+	"github.com/alexlisong/go-nebulas/util/byteutils"
+)
+
+// algorithmNames is the json name of signature algorithms
+var algorithmNames = map[keystore.Algorithm]string{
+	keystore.SECP256K1: "secp256k1",
+}
+
+// txJSON is the json form of tx for logging and rpc,
+// addresses and bytes in hex, integers as decimal strings and timestamp in RFC3339.
+type txJSON struct {
+	Hash            string `json:"hash"`
+	ChainID         uint32 `json:"chainID"`
+	From            string `json:"from"`
+	To              string `json:"to"`
+	Value           string `json:"value"`
+	Nonce           string `json:"nonce"`
+	Timestamp       string `json:"timestamp"`
+	Type            string `json:"type"`
+	Data            string `json:"data"`
+	GasPrice        string `json:"gasPrice"`
+	GasLimit        string `json:"gasLimit"`
+	Salt            string `json:"salt,omitempty"`
+	TipRecipient    string `json:"tipRecipient,omitempty"`
+	EphemeralPubKey string `json:"ephemeralPubKey,omitempty"`
+	PowNonce        string `json:"powNonce,omitempty"`
+	HashAlg         uint32 `json:"hashAlg,omitempty"`
+	ValidFrom       int64  `json:"validFrom,omitempty"`
+	Deadline        int64  `json:"deadline,omitempty"`
+	ForkMarker      string `json:"forkMarker,omitempty"`
+	Alg             string `json:"alg"`
+	Sign            string `json:"sign"`
+}
+
+func addressJSON(addr *Address) string {
+	if addr == nil || len(addr.address) == 0 {
+		return ""
+	}
+	return addr.ToHex()
+}
+
+func uint128JSON(u *util.Uint128) string {
+	if u == nil {
+		return ""
+	}
+	return u.String()
+}
+
+func parseBytesJSON(s string) ([]byte, error) {
+	if s == "" {
+		return nil, nil
+	}
+	return byteutils.FromHex(s)
+}
+
+// parseUint128JSON return the fixed size bytes of the decimal s, nil if s is empty.
+func parseUint128JSON(s string) ([]byte, error) {
+	if s == "" {
+		return nil, nil
+	}
+	u, err := util.NewUint128FromString(s)
+	if err != nil {
+		return nil, err
+	}
+	return u.ToFixedSizeByteSlice()
+}
+
+// MarshalJSON return the json of tx, fields not set yet are empty, so it's safe to log unsigned txs.
+func (tx *Transaction) MarshalJSON() ([]byte, error) {
+	data := &txJSON{
+		Hash:            byteutils.Hex(tx.hash),
+		ChainID:         tx.chainID,
+		From:            addressJSON(tx.from),
+		To:              addressJSON(tx.to),
+		Value:           uint128JSON(tx.value),
+		Nonce:           strconv.FormatUint(tx.nonce, 10),
+		Timestamp:       time.Unix(tx.timestamp, 0).UTC().Format(time.RFC3339),
+		GasPrice:        uint128JSON(tx.gasPrice),
+		GasLimit:        uint128JSON(tx.gasLimit),
+		Salt:            byteutils.Hex(tx.salt),
+		TipRecipient:    addressJSON(tx.tipRecipient),
+		EphemeralPubKey: byteutils.Hex(tx.ephemeralPubKey),
+		HashAlg:         uint32(tx.hashAlg),
+		ValidFrom:       tx.validFrom,
+		Deadline:        tx.deadline,
+		ForkMarker:      byteutils.Hex(tx.forkMarker),
+		Sign:            byteutils.Hex(tx.sign),
+	}
+	if tx.powNonce != 0 {
+		data.PowNonce = strconv.FormatUint(tx.powNonce, 10)
+	}
+	if tx.data != nil {
+		data.Type = tx.data.Type
+		data.Data = byteutils.Hex(tx.data.Payload)
+	}
+	if tx.alg != 0 {
+		name, ok := algorithmNames[tx.alg]
+		if !ok {
+			name = strconv.Itoa(int(tx.alg))
+		}
+		data.Alg = name
+	}
+	return json.Marshal(data)
+}
+
+// UnmarshalJSON set tx from the json made by MarshalJSON, the fields are checked as FromProto checks the wire,
+// so a partial tx is rejected instead of reaching the rest of core with nil fields.
+func (tx *Transaction) UnmarshalJSON(b []byte) error {
+	data := new(txJSON)
+	if err := json.Unmarshal(b, data); err != nil {
+		return err
+	}
+	msg, err := data.toProto()
+	if err != nil {
+		return err
+	}
+	ntx := new(Transaction)
+	if err := ntx.FromProto(msg); err != nil {
+		return err
+	}
+	*tx = *ntx
+	return nil
+}
+
+// toProto convert data to the wire message, a missing field is left empty for FromProto to reject.
+func (data *txJSON) toProto() (*corepb.Transaction, error) {
+	msg := &corepb.Transaction{
+		ChainId:   data.ChainID,
+		HashAlg:   data.HashAlg,
+		ValidFrom: data.ValidFrom,
+		Deadline:  data.Deadline,
+	}
+	var err error
+	if msg.Hash, err = parseBytesJSON(data.Hash); err != nil {
+		return nil, err
+	}
+	if msg.From, err = parseBytesJSON(data.From); err != nil {
+		return nil, err
+	}
+	if msg.To, err = parseBytesJSON(data.To); err != nil {
+		return nil, err
+	}
+	if msg.Value, err = parseUint128JSON(data.Value); err != nil {
+		return nil, err
+	}
+	if msg.Nonce, err = strconv.ParseUint(data.Nonce, 10, 64); err != nil {
+		return nil, err
+	}
+	timestamp, err := time.Parse(time.RFC3339, data.Timestamp)
+	if err != nil {
+		return nil, err
+	}
+	msg.Timestamp = timestamp.Unix()
+	payload, err := parseBytesJSON(data.Data)
+	if err != nil {
+		return nil, err
+	}
+	msg.Data = &corepb.Data{Type: data.Type, Payload: payload}
+	if msg.GasPrice, err = parseUint128JSON(data.GasPrice); err != nil {
+		return nil, err
+	}
+	if msg.GasLimit, err = parseUint128JSON(data.GasLimit); err != nil {
+		return nil, err
+	}
+	if msg.Salt, err = parseBytesJSON(data.Salt); err != nil {
+		return nil, err
+	}
+	if msg.TipRecipient, err = parseBytesJSON(data.TipRecipient); err != nil {
+		return nil, err
+	}
+	if msg.EphemeralPubKey, err = parseBytesJSON(data.EphemeralPubKey); err != nil {
+		return nil, err
+	}
+	if data.PowNonce != "" {
+		if msg.PowNonce, err = strconv.ParseUint(data.PowNonce, 10, 64); err != nil {
+			return nil, err
+		}
+	}
+	if msg.ForkMarker, err = parseBytesJSON(data.ForkMarker); err != nil {
+		return nil, err
+	}
+	if data.Alg != "" {
+		found := false
+		for alg, name := range algorithmNames {
+			if name == data.Alg {
+				msg.Alg, found = uint32(alg), true
+			}
+		}
+		if !found {
+			alg, err := strconv.ParseUint(data.Alg, 10, 32)
+			if err != nil {
+				return nil, ErrInvalidArgument
+			}
+			msg.Alg = uint32(alg)
+		}
+	}
+	if msg.Sign, err = parseBytesJSON(data.Sign); err != nil {
+		return nil, err
+	}
+	return msg, nil
+}
@@ -0,0 +1,94 @@
+// Copyright (C) 2017 go-nebulas authors
+//
+// This file is part of the go-nebulas library.
+//
+// the go-nebulas library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// the go-nebulas library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with the go-nebulas library.  If not, see <http://www.gnu.org/licenses/>.
+//
+
+package core
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/alexlisong/go-nebulas/util/byteutils"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTransaction_JSON(t *testing.T) {
+	tx := mockCallTransaction(1, 3, "transfer", `["n1FF1nz6tarkDVwWQkMnnwFPuPKUaQTdptE", "100"]`)
+	assert.Nil(t, tx.SetReplaySalt(mockReplaySalt()))
+	tx.SetTipRecipient(mockAddress())
+	assert.Nil(t, signTx(tx))
+
+	data, err := json.Marshal(tx)
+	assert.Nil(t, err)
+	var fields map[string]interface{}
+	assert.Nil(t, json.Unmarshal(data, &fields))
+	assert.Equal(t, tx.hash.String(), fields["hash"])
+	assert.Equal(t, tx.from.ToHex(), fields["from"])
+	assert.Equal(t, "3", fields["nonce"])
+	assert.Equal(t, tx.value.String(), fields["value"])
+	assert.Equal(t, "secp256k1", fields["alg"])
+	assert.Regexp(t, `^\d{4}-\d{2}-\d{2}T\d{2}:\d{2}:\d{2}Z$`, fields["timestamp"])
+
+	decoded := new(Transaction)
+	assert.Nil(t, json.Unmarshal(data, decoded))
+	assert.True(t, tx.Equals(decoded))
+	assert.Equal(t, tx.salt, decoded.salt)
+	assert.Equal(t, tx.tipRecipient, decoded.tipRecipient)
+	assert.Nil(t, decoded.VerifyIntegrity(1))
+	again, err := json.Marshal(decoded)
+	assert.Nil(t, err)
+	assert.Equal(t, data, again)
+
+	assert.NotNil(t, json.Unmarshal([]byte(`{"nonce":"-1"}`), new(Transaction)))
+	assert.NotNil(t, json.Unmarshal([]byte(`{"from":"n1x","nonce":"1"}`), new(Transaction)))
+}
+
+func TestTransaction_JSONPartial(t *testing.T) {
+	// partial txs are logged before signing, so they marshal, but they are not valid txs.
+	for _, tx := range []*Transaction{{}, {from: &Address{}}, mockNormalTransaction(1, 1)} {
+		data, err := json.Marshal(tx)
+		assert.Nil(t, err)
+		assert.NotNil(t, json.Unmarshal(data, new(Transaction)))
+	}
+	assert.NotNil(t, json.Unmarshal([]byte(`{}`), new(Transaction)))
+
+	signed := mockNormalTransaction(1, 1)
+	assert.Nil(t, signTx(signed))
+	data, err := json.Marshal(signed)
+	assert.Nil(t, err)
+	// the fields are checked as on the wire.
+	for _, field := range []string{"from", "to", "value", "gasPrice", "gasLimit", "alg"} {
+		var fields map[string]interface{}
+		assert.Nil(t, json.Unmarshal(data, &fields))
+		delete(fields, field)
+		partial, _ := json.Marshal(fields)
+		assert.NotNil(t, json.Unmarshal(partial, new(Transaction)), field)
+	}
+	for field, value := range map[string]interface{}{
+		"gasPrice":        "0",
+		"salt":            "01",
+		"ephemeralPubKey": "01",
+		"alg":             "255",
+		"data":            byteutils.Hex(make([]byte, MaxDataPayLoadLength+1)),
+	} {
+		var fields map[string]interface{}
+		assert.Nil(t, json.Unmarshal(data, &fields))
+		fields[field] = value
+		invalid, _ := json.Marshal(fields)
+		assert.NotNil(t, json.Unmarshal(invalid, new(Transaction)), field)
+	}
+}
@@ -0,0 +1,125 @@
+// Copyright (C) 2017 go-nebulas authors
+//
+// This file is part of the go-nebulas library.
+//
+// the go-nebulas library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// the go-nebulas library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with the go-nebulas library.  If not, see <http://www.gnu.org/licenses/>.
+//
+
+package core
+
+import (
+	"encoding/json"
+
+	"github.com/alexlisong/go-nebulas/util"
+)
+
+// MultiSendFunction is the contract function of multi-send txs,
+// called with args [[to...], [value...]] and the sum of values as tx value.
+const MultiSendFunction = "multiSend"
+
+// MultiSendOutput is a receiver of a multi-send.
+type MultiSendOutput struct {
+	To    *Address
+	Value *util.Uint128
+}
+
+// NewMultiSendTransaction create a multi-send tx to the contract, tx value is the sum of the outputs.
+func NewMultiSendTransaction(chainID uint32, from, contract *Address, outputs []*MultiSendOutput, nonce uint64, gasPrice, gasLimit *util.Uint128) (*Transaction, error) {
+	if len(outputs) == 0 {
+		return nil, ErrInvalidMultiSendOutputs
+	}
+	tos := make([]string, len(outputs))
+	values := make([]string, len(outputs))
+	total := util.NewUint128()
+	for i, output := range outputs {
+		if output == nil || output.To == nil || output.Value == nil {
+			return nil, ErrInvalidMultiSendOutputs
+		}
+		tos[i] = output.To.String()
+		values[i] = output.Value.String()
+		var err error
+		if total, err = total.Add(output.Value); err != nil {
+			return nil, err
+		}
+	}
+	args, err := json.Marshal([][]string{tos, values})
+	if err != nil {
+		return nil, err
+	}
+	payload, err := NewCallPayload(MultiSendFunction, string(args))
+	if err != nil {
+		return nil, err
+	}
+	data, err := payload.ToBytes()
+	if err != nil {
+		return nil, err
+	}
+	return NewTransaction(chainID, from, contract, total, nonce, TxPayloadCallType, data, gasPrice, gasLimit)
+}
+
+// MultiSendOutputs return the outputs of a multi-send tx.
+func (tx *Transaction) MultiSendOutputs() ([]*MultiSendOutput, error) {
+	if tx.Type() != TxPayloadCallType {
+		return nil, ErrNotMultiSend
+	}
+	payload, err := LoadCallPayload(tx.Data())
+	if err != nil || payload.Function != MultiSendFunction {
+		return nil, ErrNotMultiSend
+	}
+	var args [][]string
+	if err := json.Unmarshal([]byte(payload.Args), &args); err != nil || len(args) != 2 || len(args[0]) != len(args[1]) || len(args[0]) == 0 {
+		return nil, ErrInvalidMultiSendOutputs
+	}
+	outputs := make([]*MultiSendOutput, len(args[0]))
+	for i := range args[0] {
+		to, err := AddressParse(args[0][i])
+		if err != nil {
+			return nil, ErrInvalidMultiSendOutputs
+		}
+		value, err := util.NewUint128FromString(args[1][i])
+		if err != nil {
+			return nil, ErrInvalidMultiSendOutputs
+		}
+		outputs[i] = &MultiSendOutput{To: to, Value: value}
+	}
+	return outputs, nil
+}
+
+// SplitMultiSend split a multi-send tx into unsigned txs with at most maxOutputs outputs each,
+// nonces are sequential from startNonce, the outputs and their order are kept.
+func (tx *Transaction) SplitMultiSend(maxOutputs int, startNonce uint64) (Transactions, error) {
+	if maxOutputs <= 0 {
+		return nil, ErrInvalidArgument
+	}
+	outputs, err := tx.MultiSendOutputs()
+	if err != nil {
+		return nil, err
+	}
+	if last := uint64((len(outputs) - 1) / maxOutputs); startNonce+last < startNonce {
+		return nil, ErrNonceOverflow
+	}
+	var txs Transactions
+	for i := 0; i < len(outputs); i += maxOutputs {
+		end := i + maxOutputs
+		if end > len(outputs) {
+			end = len(outputs)
+		}
+		part, err := NewMultiSendTransaction(tx.chainID, tx.from, tx.to, outputs[i:end], startNonce+uint64(len(txs)), tx.gasPrice, tx.gasLimit)
+		if err != nil {
+			return nil, err
+		}
+		txs = append(txs, part)
+	}
+	return txs, nil
+}
@@ -0,0 +1,80 @@
+// Copyright (C) 2017 go-nebulas authors
+//
+// This file is part of the go-nebulas library.
+//
+// the go-nebulas library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// the go-nebulas library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with the go-nebulas library.  If not, see <http://www.gnu.org/licenses/>.
+//
+
+package core
+
+import (
+	"math"
+	"testing"
+
+	"github.com/alexlisong/go-nebulas/util"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTransaction_SplitMultiSend(t *testing.T) {
+	from := mockAddress()
+	contract, _ := AddressParse("n1sLnoc7j57YfzAVP8tJ3yK5a2i56QrTDdK")
+	receivers := []*Address{mockAddress(), mockAddress(), mockAddress()}
+	var outputs []*MultiSendOutput
+	for i := 0; i < 23; i++ {
+		outputs = append(outputs, &MultiSendOutput{To: receivers[i%len(receivers)], Value: util.NewUint128FromUint(uint64(i*1000 + 1))})
+	}
+	tx, err := NewMultiSendTransaction(1, from, contract, outputs, 5, TransactionGasPrice, TransactionMaxGas)
+	assert.Nil(t, err)
+	got, err := tx.MultiSendOutputs()
+	assert.Nil(t, err)
+	assert.Equal(t, outputs, got)
+
+	txs, err := tx.SplitMultiSend(5, 10)
+	assert.Nil(t, err)
+	assert.Equal(t, 5, len(txs))
+
+	var split []*MultiSendOutput
+	total := util.NewUint128()
+	for i, part := range txs {
+		assert.Equal(t, uint64(10+i), part.Nonce())
+		assert.True(t, part.from.Equals(from))
+		assert.True(t, part.to.Equals(contract))
+		partOutputs, err := part.MultiSendOutputs()
+		assert.Nil(t, err)
+		assert.True(t, len(partOutputs) <= 5)
+		partValue := util.NewUint128()
+		for _, output := range partOutputs {
+			partValue, _ = partValue.Add(output.Value)
+		}
+		assert.Equal(t, partValue, part.value)
+		total, _ = total.Add(part.value)
+		split = append(split, partOutputs...)
+	}
+	// outputs are conserved in order and value
+	assert.Equal(t, outputs, split)
+	assert.Equal(t, tx.value, total)
+
+	single, err := tx.SplitMultiSend(100, 1)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(single))
+
+	_, err = tx.SplitMultiSend(0, 1)
+	assert.Equal(t, ErrInvalidArgument, err)
+	_, err = tx.SplitMultiSend(5, math.MaxUint64-3)
+	assert.Equal(t, ErrNonceOverflow, err)
+	_, err = mockNormalTransaction(1, 1).SplitMultiSend(5, 1)
+	assert.Equal(t, ErrNotMultiSend, err)
+	_, err = mockCallTransaction(1, 1, MultiSendFunction, `[["n1sLnoc7j57YfzAVP8tJ3yK5a2i56QrTDdK"], []]`).SplitMultiSend(5, 1)
+	assert.Equal(t, ErrInvalidMultiSendOutputs, err)
+}
@@ -0,0 +1,45 @@
+// Copyright (C) 2017 go-nebulas authors
+//
+// This file is part of the go-nebulas library.
+//
+// the go-nebulas library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// the go-nebulas library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with the go-nebulas library.  If not, see <http://www.gnu.org/licenses/>.
+//
+
+package core
+
+import (
+	"github.com/alexlisong/go-nebulas/crypto/keystore"
+)
+
+// algorithmPairing is a combination of the hash function of the tx hash and the signature algorithm signing it.
+type algorithmPairing struct {
+	hashAlg TxHashAlgorithm
+	sigAlg  keystore.Algorithm
+}
+
+// allowedAlgorithmPairings are the combinations a tx may be signed with, a new hash function
+// or signature algorithm is only accepted once its pairings are reviewed and listed here.
+// secp256k1 signs 256 bits digests, both sha3-256 and Poseidon give one at full strength.
+var allowedAlgorithmPairings = map[algorithmPairing]bool{
+	{TxHashSha3256, keystore.SECP256K1}:  true,
+	{TxHashPoseidon, keystore.SECP256K1}: true,
+}
+
+// ValidateAlgorithmPairing check the hash function and signature algorithm of tx are an allowed combination.
+func (tx *Transaction) ValidateAlgorithmPairing() error {
+	if !allowedAlgorithmPairings[algorithmPairing{tx.hashAlg, tx.alg}] {
+		return ErrInsecureAlgorithmPairing
+	}
+	return nil
+}
@@ -0,0 +1,55 @@
+// Copyright (C) 2017 go-nebulas authors
+//
+// This file is part of the go-nebulas library.
+//
+// the go-nebulas library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// the go-nebulas library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with the go-nebulas library.  If not, see <http://www.gnu.org/licenses/>.
+//
+
+package core
+
+import (
+	"testing"
+
+	"github.com/alexlisong/go-nebulas/crypto/keystore"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTransaction_ValidateAlgorithmPairing(t *testing.T) {
+	tx := mockNormalTransaction(1, 1)
+	assert.Nil(t, signTx(tx))
+	assert.Nil(t, tx.ValidateAlgorithmPairing())
+
+	poseidon := mockNormalTransaction(1, 2)
+	assert.Nil(t, poseidon.SetHashAlgorithm(TxHashPoseidon))
+	assert.Nil(t, signTx(poseidon))
+	assert.Nil(t, poseidon.ValidateAlgorithmPairing())
+
+	tests := []struct {
+		name    string
+		hashAlg TxHashAlgorithm
+		sigAlg  keystore.Algorithm
+	}{
+		{"unsigned", TxHashSha3256, 0},
+		{"unknown signature", TxHashSha3256, keystore.Algorithm(100)},
+		{"unknown hash", TxHashAlgorithm(2), keystore.SECP256K1},
+		{"key derivation as signature", TxHashPoseidon, keystore.SCRYPT},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tx := mockNormalTransaction(1, 1)
+			tx.hashAlg, tx.alg = tt.hashAlg, tt.sigAlg
+			assert.Equal(t, ErrInsecureAlgorithmPairing, tx.ValidateAlgorithmPairing())
+		})
+	}
+}
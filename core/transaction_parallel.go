@@ -0,0 +1,60 @@
+// Copyright (C) 2017 go-nebulas authors
+//
+// This file is part of the go-nebulas library.
+//
+// the go-nebulas library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// the go-nebulas library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with the go-nebulas library.  If not, see <http://www.gnu.org/licenses/>.
+//
+
+package core
+
+import (
+	"github.com/alexlisong/go-nebulas/util/byteutils"
+)
+
+// ReorderForParallelism schedule txs into batches to be executed one after another,
+// the txs of a batch touch disjoint addresses so they can be executed in parallel.
+// txs have no access lists, a tx touches its from, to and tip recipient.
+// each tx is put in the batch after the last one touching any of its addresses,
+// so txs of a sender are in nonce order across batches and the schedule only depends on txs.
+func (txs Transactions) ReorderForParallelism() [][]*Transaction {
+	var batches [][]*Transaction
+	lastBatch := make(map[string]int)
+	for _, tx := range txs.inSenderNonceOrder() {
+		touched := tx.touchedAddresses()
+		batch := 0
+		for _, addr := range touched {
+			if last, ok := lastBatch[addr]; ok && last+1 > batch {
+				batch = last + 1
+			}
+		}
+		if batch == len(batches) {
+			batches = append(batches, nil)
+		}
+		batches[batch] = append(batches[batch], tx)
+		for _, addr := range touched {
+			lastBatch[addr] = batch
+		}
+	}
+	return batches
+}
+
+func (tx *Transaction) touchedAddresses() []string {
+	touched := []string{byteutils.Hex(tx.from.address)}
+	for _, addr := range []*Address{tx.to, tx.tipRecipient} {
+		if addr != nil {
+			touched = append(touched, byteutils.Hex(addr.address))
+		}
+	}
+	return touched
+}
@@ -0,0 +1,72 @@
+// Copyright (C) 2017 go-nebulas authors
+//
+// This file is part of the go-nebulas library.
+//
+// the go-nebulas library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// the go-nebulas library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with the go-nebulas library.  If not, see <http://www.gnu.org/licenses/>.
+//
+
+package core
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func assertParallelSchedule(t *testing.T, txs Transactions, batches [][]*Transaction) {
+	count := 0
+	for _, batch := range batches {
+		assert.NotEmpty(t, batch)
+		seen := make(map[string]bool)
+		for _, tx := range batch {
+			for _, addr := range tx.touchedAddresses() {
+				assert.False(t, seen[addr], "conflicting txs in a batch")
+				seen[addr] = true
+			}
+		}
+		count += len(batch)
+	}
+	assert.Equal(t, len(txs), count)
+}
+
+func TestTransactions_ReorderForParallelism(t *testing.T) {
+	a, b, c, d := mockAddress(), mockAddress(), mockAddress(), mockAddress()
+
+	disjoint := Transactions{mockTransfer(a, b, 1), mockTransfer(c, d, 1)}
+	batches := disjoint.ReorderForParallelism()
+	assertParallelSchedule(t, disjoint, batches)
+	assert.Equal(t, [][]*Transaction{{disjoint[0], disjoint[1]}}, batches)
+
+	// b receives then sends, c is touched by both.
+	chained := Transactions{mockTransfer(a, b, 1), mockTransfer(b, c, 1), mockTransfer(d, c, 1)}
+	batches = chained.ReorderForParallelism()
+	assertParallelSchedule(t, chained, batches)
+	assert.Equal(t, [][]*Transaction{{chained[0]}, {chained[1]}, {chained[2]}}, batches)
+
+	// txs of a sender are scheduled in nonce order, whatever their order in txs.
+	third, first, second := mockTransfer(a, b, 3), mockTransfer(a, c, 1), mockTransfer(a, d, 2)
+	other := mockTransfer(b, c, 1)
+	mixed := Transactions{third, other, first, second}
+	batches = mixed.ReorderForParallelism()
+	assertParallelSchedule(t, mixed, batches)
+	assert.Equal(t, [][]*Transaction{{first}, {other, second}, {third}}, batches)
+
+	// a tip recipient is touched too.
+	tipped := mockTransfer(c, d, 1)
+	tipped.SetTipRecipient(a)
+	withTip := Transactions{mockTransfer(a, b, 1), tipped}
+	assert.Equal(t, 2, len(withTip.ReorderForParallelism()))
+
+	assert.Nil(t, Transactions{}.ReorderForParallelism())
+}
@@ -290,6 +290,10 @@ func (pool *TransactionPool) Push(tx *Transaction) error {
 		return ErrOutOfGasLimit
 	}
 
+	if err := tx.VerifyIntrinsicGas(); err != nil {
+		return err
+	}
+
 	// verify hash & sign of tx
 	if err := tx.VerifyIntegrity(pool.bc.chainID); err != nil {
 		return err
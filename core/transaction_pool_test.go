@@ -30,6 +30,9 @@ import (
 	"github.com/stretchr/testify/assert"
 )
 
+// mockPoolReceiver is the receiver of txs in pool tests, the pool doesn't depend on it
+var mockPoolReceiver, _ = AddressParse("n1TV3sU6jyzR4rJ1D7jCAmtVGSntJagXZHC")
+
 func TestTransactionPool_1(t *testing.T) {
 	ks := keystore.DefaultKS
 	priv1 := secp256k1.GeneratePrivateKey()
@@ -78,17 +81,17 @@ func TestTransactionPool_1(t *testing.T) {
 	txPool.setEventEmitter(bc.eventEmitter)
 
 	gasLimit, _ := util.NewUint128FromInt(200000)
-	tx1, _ := NewTransaction(bc.ChainID(), from, &Address{address: []byte("to")}, util.NewUint128(), 10, TxPayloadBinaryType, []byte("1"), TransactionGasPrice, gasLimit)
-	tx2, _ := NewTransaction(bc.ChainID(), other, &Address{address: []byte("to")}, util.NewUint128(), 1, TxPayloadBinaryType, []byte("2"), heighPrice, gasLimit)
-	tx3, _ := NewTransaction(bc.ChainID(), from, &Address{address: []byte("to")}, util.NewUint128(), 1, TxPayloadBinaryType, []byte("3"), TransactionGasPrice, gasLimit)
+	tx1, _ := NewTransaction(bc.ChainID(), from, mockPoolReceiver, util.NewUint128(), 10, TxPayloadBinaryType, []byte("1"), TransactionGasPrice, gasLimit)
+	tx2, _ := NewTransaction(bc.ChainID(), other, mockPoolReceiver, util.NewUint128(), 1, TxPayloadBinaryType, []byte("2"), heighPrice, gasLimit)
+	tx3, _ := NewTransaction(bc.ChainID(), from, mockPoolReceiver, util.NewUint128(), 1, TxPayloadBinaryType, []byte("3"), TransactionGasPrice, gasLimit)
 
-	tx4, _ := NewTransaction(bc.ChainID(), from, &Address{address: []byte("to")}, util.NewUint128(), 2, TxPayloadBinaryType, []byte("4"), TransactionGasPrice, gasLimit)
-	tx5, _ := NewTransaction(bc.ChainID()+1, from, &Address{address: []byte("to")}, util.NewUint128(), 0, TxPayloadBinaryType, []byte("5"), TransactionGasPrice, gasLimit)
+	tx4, _ := NewTransaction(bc.ChainID(), from, mockPoolReceiver, util.NewUint128(), 2, TxPayloadBinaryType, []byte("4"), TransactionGasPrice, gasLimit)
+	tx5, _ := NewTransaction(bc.ChainID()+1, from, mockPoolReceiver, util.NewUint128(), 0, TxPayloadBinaryType, []byte("5"), TransactionGasPrice, gasLimit)
 
-	tx6, _ := NewTransaction(bc.ChainID(), other2, &Address{[]byte("to")}, util.NewUint128(), 1, TxPayloadBinaryType, []byte("6"), TransactionGasPrice, gasLimit)
-	tx7, _ := NewTransaction(bc.ChainID(), other, &Address{[]byte("to")}, util.NewUint128(), 1, TxPayloadBinaryType, []byte("7"), heighPrice, gasLimit)
+	tx6, _ := NewTransaction(bc.ChainID(), other2, mockPoolReceiver, util.NewUint128(), 1, TxPayloadBinaryType, []byte("6"), TransactionGasPrice, gasLimit)
+	tx7, _ := NewTransaction(bc.ChainID(), other, mockPoolReceiver, util.NewUint128(), 1, TxPayloadBinaryType, []byte("7"), heighPrice, gasLimit)
 
-	tx8, _ := NewTransaction(bc.ChainID(), other3, &Address{[]byte("to")}, util.NewUint128(), 1, TxPayloadBinaryType, []byte("8"), heighPrice, gasLimit)
+	tx8, _ := NewTransaction(bc.ChainID(), other3, mockPoolReceiver, util.NewUint128(), 1, TxPayloadBinaryType, []byte("8"), heighPrice, gasLimit)
 
 	txs := []*Transaction{tx1, tx2, tx3, tx4, tx5, tx6, tx7, tx8}
 
@@ -171,13 +174,13 @@ func TestTransactionPool(t *testing.T) {
 
 	gasLimit, _ := util.NewUint128FromInt(200000)
 
-	tx1, _ := NewTransaction(bc.ChainID(), from, &Address{[]byte("to")}, util.NewUint128(), 10, TxPayloadBinaryType, []byte("1"), TransactionGasPrice, gasLimit)
-	tx2, _ := NewTransaction(bc.ChainID(), other, &Address{[]byte("to")}, util.NewUint128(), 2, TxPayloadBinaryType, []byte("2"), TransactionGasPrice, gasLimit)
-	tx3, _ := NewTransaction(bc.ChainID(), from, &Address{[]byte("to")}, util.NewUint128(), 1, TxPayloadBinaryType, []byte("3"), TransactionGasPrice, gasLimit)
-	tx4, _ := NewTransaction(bc.ChainID(), from, &Address{[]byte("to")}, util.NewUint128(), 2, TxPayloadBinaryType, []byte("4"), TransactionGasPrice, gasLimit)
-	tx5, _ := NewTransaction(bc.ChainID()+1, from, &Address{[]byte("to")}, util.NewUint128(), 0, TxPayloadBinaryType, []byte("5"), TransactionGasPrice, gasLimit)
-	tx6, _ := NewTransaction(bc.ChainID(), other, &Address{[]byte("to")}, util.NewUint128(), 1, TxPayloadBinaryType, []byte("6"), heighPrice, gasLimit)
-	tx7, _ := NewTransaction(bc.ChainID(), from, &Address{[]byte("to")}, util.NewUint128(), 1, TxPayloadBinaryType, []byte("7"), heighPrice, gasLimit)
+	tx1, _ := NewTransaction(bc.ChainID(), from, mockPoolReceiver, util.NewUint128(), 10, TxPayloadBinaryType, []byte("1"), TransactionGasPrice, gasLimit)
+	tx2, _ := NewTransaction(bc.ChainID(), other, mockPoolReceiver, util.NewUint128(), 2, TxPayloadBinaryType, []byte("2"), TransactionGasPrice, gasLimit)
+	tx3, _ := NewTransaction(bc.ChainID(), from, mockPoolReceiver, util.NewUint128(), 1, TxPayloadBinaryType, []byte("3"), TransactionGasPrice, gasLimit)
+	tx4, _ := NewTransaction(bc.ChainID(), from, mockPoolReceiver, util.NewUint128(), 2, TxPayloadBinaryType, []byte("4"), TransactionGasPrice, gasLimit)
+	tx5, _ := NewTransaction(bc.ChainID()+1, from, mockPoolReceiver, util.NewUint128(), 0, TxPayloadBinaryType, []byte("5"), TransactionGasPrice, gasLimit)
+	tx6, _ := NewTransaction(bc.ChainID(), other, mockPoolReceiver, util.NewUint128(), 1, TxPayloadBinaryType, []byte("6"), heighPrice, gasLimit)
+	tx7, _ := NewTransaction(bc.ChainID(), from, mockPoolReceiver, util.NewUint128(), 1, TxPayloadBinaryType, []byte("7"), heighPrice, gasLimit)
 
 	txs := []*Transaction{tx1, tx2, tx3, tx4, tx5, tx6, tx7}
 
@@ -266,6 +269,11 @@ func TestPushTxs(t *testing.T) {
 	assert.Equal(t, err, ErrInvalidGasLimit)
 	txs := []*Transaction{tx1}
 	assert.Equal(t, txPool.Push(txs[0]), ErrBelowGasPrice)
+
+	tx2, err := NewTransaction(bc.ChainID(), from, to, util.NewUint128(), 10, TxPayloadBinaryType, []byte("datadata"), TransactionGasPrice, MinGasCountPerTransaction)
+	assert.Nil(t, err)
+	assert.Nil(t, tx2.Sign(signature1))
+	assert.Equal(t, ErrGasLimitBelowIntrinsic, txPool.Push(tx2))
 }
 
 func TestTransactionPool_Pop(t *testing.T) {
@@ -297,11 +305,11 @@ func TestTransactionPool_Pop(t *testing.T) {
 
 	assert.Equal(t, highPrice.Cmp(TransactionGasPrice), 1)
 	gasLimit, _ := util.NewUint128FromInt(200000)
-	tx1, _ := NewTransaction(bc.ChainID(), from, &Address{[]byte("to")}, util.NewUint128(), 3, TxPayloadBinaryType, []byte("1"), TransactionGasPrice, gasLimit)
-	tx2, _ := NewTransaction(bc.ChainID(), other, &Address{[]byte("to")}, util.NewUint128(), 2, TxPayloadBinaryType, []byte("2"), highPrice, gasLimit)
-	tx3, _ := NewTransaction(bc.ChainID(), from, &Address{[]byte("to")}, util.NewUint128(), 2, TxPayloadBinaryType, []byte("3"), TransactionGasPrice, gasLimit)
-	tx4, _ := NewTransaction(bc.ChainID(), from, &Address{[]byte("to")}, util.NewUint128(), 1, TxPayloadBinaryType, []byte("4"), TransactionGasPrice, gasLimit)
-	tx5, _ := NewTransaction(bc.ChainID(), other, &Address{[]byte("to")}, util.NewUint128(), 1, TxPayloadBinaryType, []byte("5"), highPrice, gasLimit)
+	tx1, _ := NewTransaction(bc.ChainID(), from, mockPoolReceiver, util.NewUint128(), 3, TxPayloadBinaryType, []byte("1"), TransactionGasPrice, gasLimit)
+	tx2, _ := NewTransaction(bc.ChainID(), other, mockPoolReceiver, util.NewUint128(), 2, TxPayloadBinaryType, []byte("2"), highPrice, gasLimit)
+	tx3, _ := NewTransaction(bc.ChainID(), from, mockPoolReceiver, util.NewUint128(), 2, TxPayloadBinaryType, []byte("3"), TransactionGasPrice, gasLimit)
+	tx4, _ := NewTransaction(bc.ChainID(), from, mockPoolReceiver, util.NewUint128(), 1, TxPayloadBinaryType, []byte("4"), TransactionGasPrice, gasLimit)
+	tx5, _ := NewTransaction(bc.ChainID(), other, mockPoolReceiver, util.NewUint128(), 1, TxPayloadBinaryType, []byte("5"), highPrice, gasLimit)
 	txs := []*Transaction{tx1, tx2, tx3, tx4, tx5}
 
 	assert.Nil(t, txs[0].Sign(signature1))
@@ -356,11 +364,11 @@ func TestTransactionPoolBucketUpdateTimeAndEvict(t *testing.T) {
 
 	assert.Equal(t, highPrice.Cmp(TransactionGasPrice), 1)
 	gasLimit, _ := util.NewUint128FromInt(200000)
-	tx1, _ := NewTransaction(bc.ChainID(), from, &Address{[]byte("to")}, util.NewUint128(), 3, TxPayloadBinaryType, []byte("1"), TransactionGasPrice, gasLimit)
-	tx2, _ := NewTransaction(bc.ChainID(), other, &Address{[]byte("to")}, util.NewUint128(), 2, TxPayloadBinaryType, []byte("2"), highPrice, gasLimit)
-	tx3, _ := NewTransaction(bc.ChainID(), from, &Address{[]byte("to")}, util.NewUint128(), 2, TxPayloadBinaryType, []byte("3"), TransactionGasPrice, gasLimit)
-	tx4, _ := NewTransaction(bc.ChainID(), from, &Address{[]byte("to")}, util.NewUint128(), 1, TxPayloadBinaryType, []byte("4"), TransactionGasPrice, gasLimit)
-	tx5, _ := NewTransaction(bc.ChainID(), other, &Address{[]byte("to")}, util.NewUint128(), 1, TxPayloadBinaryType, []byte("5"), highPrice, gasLimit)
+	tx1, _ := NewTransaction(bc.ChainID(), from, mockPoolReceiver, util.NewUint128(), 3, TxPayloadBinaryType, []byte("1"), TransactionGasPrice, gasLimit)
+	tx2, _ := NewTransaction(bc.ChainID(), other, mockPoolReceiver, util.NewUint128(), 2, TxPayloadBinaryType, []byte("2"), highPrice, gasLimit)
+	tx3, _ := NewTransaction(bc.ChainID(), from, mockPoolReceiver, util.NewUint128(), 2, TxPayloadBinaryType, []byte("3"), TransactionGasPrice, gasLimit)
+	tx4, _ := NewTransaction(bc.ChainID(), from, mockPoolReceiver, util.NewUint128(), 1, TxPayloadBinaryType, []byte("4"), TransactionGasPrice, gasLimit)
+	tx5, _ := NewTransaction(bc.ChainID(), other, mockPoolReceiver, util.NewUint128(), 1, TxPayloadBinaryType, []byte("5"), highPrice, gasLimit)
 	txs := []*Transaction{tx1, tx2, tx3, tx4, tx5}
 
 	assert.Nil(t, txs[0].Sign(signature1))
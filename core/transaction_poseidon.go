@@ -0,0 +1,75 @@
+// Copyright (C) 2017 go-nebulas authors
+//
+// This file is part of the go-nebulas library.
+//
+// the go-nebulas library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// the go-nebulas library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with the go-nebulas library.  If not, see <http://www.gnu.org/licenses/>.
+//
+
+package core
+
+import (
+	"github.com/alexlisong/go-nebulas/crypto/hash"
+	"github.com/alexlisong/go-nebulas/util/byteutils"
+)
+
+// TxHashAlgorithm is the hash function of the tx hash.
+type TxHashAlgorithm uint32
+
+const (
+	// TxHashSha3256 hash the tx fields with sha3-256, the default.
+	TxHashSha3256 TxHashAlgorithm = iota
+	// TxHashPoseidon hash the tx field elements with Poseidon, cheap to prove in zk circuits.
+	TxHashPoseidon
+)
+
+func checkHashAlgorithm(alg TxHashAlgorithm) error {
+	switch alg {
+	case TxHashSha3256, TxHashPoseidon:
+		return nil
+	}
+	return ErrUnsupportedHashAlgorithm
+}
+
+// HashAlgorithm return the hash function declared by tx.
+func (tx *Transaction) HashAlgorithm() TxHashAlgorithm {
+	return tx.hashAlg
+}
+
+// SetHashAlgorithm declare the hash function of tx, the tx must be signed after.
+func (tx *Transaction) SetHashAlgorithm(alg TxHashAlgorithm) error {
+	if tx.frozen {
+		return ErrTransactionFrozen
+	}
+	if err := checkHashAlgorithm(alg); err != nil {
+		return err
+	}
+	tx.hashAlg = alg
+	tx.rawBytes = nil
+	tx.cachedHash = nil
+	return nil
+}
+
+// HashTransactionPoseidon return the Poseidon hash of the tx field elements,
+// prefixed with the field elements of the tx hash domain when it's set.
+func HashTransactionPoseidon(tx *Transaction) (byteutils.Hash, error) {
+	var elements [][]byte
+	if len(txHashDomain) > 0 {
+		elements = appendFieldElements(elements, txHashDomain)
+	}
+	fields, err := tx.ToFieldElements()
+	if err != nil {
+		return nil, err
+	}
+	return hash.Poseidon(append(elements, fields...)...)
+}
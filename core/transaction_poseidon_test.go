@@ -0,0 +1,97 @@
+// Copyright (C) 2017 go-nebulas authors
+//
+// This file is part of the go-nebulas library.
+//
+// the go-nebulas library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// the go-nebulas library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with the go-nebulas library.  If not, see <http://www.gnu.org/licenses/>.
+//
+
+package core
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/alexlisong/go-nebulas/core/pb"
+	"github.com/alexlisong/go-nebulas/crypto/hash"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTransaction_PoseidonHash(t *testing.T) {
+	tx := mockNormalTransaction(1, 1)
+	sha3Hash, err := tx.calHash()
+	assert.Nil(t, err)
+
+	assert.Nil(t, tx.SetHashAlgorithm(TxHashPoseidon))
+	assert.Equal(t, TxHashPoseidon, tx.HashAlgorithm())
+	assert.Nil(t, signTx(tx))
+	assert.Equal(t, hash.PoseidonElementSize, len(tx.hash))
+	assert.NotEqual(t, sha3Hash, tx.hash)
+
+	poseidonHash, err := HashTransactionPoseidon(tx)
+	assert.Nil(t, err)
+	assert.Equal(t, poseidonHash, tx.hash)
+	assert.Nil(t, tx.VerifyIntegrity(1))
+	assert.Equal(t, TxHashV2, tx.HashVersion())
+
+	// the hash domain is mixed into the poseidon hash too
+	SetTxHashDomain([]byte("subchain"))
+	domainHash, err := HashTransactionPoseidon(tx)
+	SetTxHashDomain(nil)
+	assert.Nil(t, err)
+	assert.NotEqual(t, poseidonHash, domainHash)
+
+	// the declared algorithm is part of what's verified
+	tampered := tx.Clone()
+	tampered.hashAlg = TxHashSha3256
+	assert.Equal(t, ErrInvalidTransactionHash, tampered.VerifyIntegrity(1))
+	tampered = tx.Clone()
+	tampered.nonce = 2
+	assert.Equal(t, ErrInvalidTransactionHash, tampered.VerifyIntegrity(1))
+
+	assert.Equal(t, ErrUnsupportedHashAlgorithm, mockNormalTransaction(1, 1).SetHashAlgorithm(TxHashAlgorithm(2)))
+	tx.hashAlg = TxHashAlgorithm(2)
+	_, err = tx.calHash()
+	assert.Equal(t, ErrUnsupportedHashAlgorithm, err)
+}
+
+func TestTransaction_PoseidonRoundTrip(t *testing.T) {
+	tx := mockNormalTransaction(1, 1)
+	assert.Nil(t, tx.SetHashAlgorithm(TxHashPoseidon))
+	assert.Nil(t, signTx(tx))
+
+	pbTx, err := tx.ToProto()
+	assert.Nil(t, err)
+	assert.Equal(t, uint32(TxHashPoseidon), pbTx.(*corepb.Transaction).HashAlg)
+	decoded := new(Transaction)
+	assert.Nil(t, decoded.FromProto(pbTx))
+	assert.Equal(t, TxHashPoseidon, decoded.HashAlgorithm())
+	assert.True(t, tx.Equals(decoded))
+	assert.Nil(t, decoded.VerifyIntegrity(1))
+
+	data, err := tx.Marshal()
+	assert.Nil(t, err)
+	decoded, err = UnmarshalTransaction(data)
+	assert.Nil(t, err)
+	assert.Nil(t, decoded.VerifyIntegrity(1))
+
+	jsonData, err := json.Marshal(tx)
+	assert.Nil(t, err)
+	decoded = new(Transaction)
+	assert.Nil(t, json.Unmarshal(jsonData, decoded))
+	assert.Equal(t, TxHashPoseidon, decoded.HashAlgorithm())
+	assert.Nil(t, decoded.VerifyIntegrity(1))
+
+	pbTx.(*corepb.Transaction).HashAlg = 2
+	assert.Equal(t, ErrUnsupportedHashAlgorithm, new(Transaction).FromProto(pbTx))
+}
@@ -0,0 +1,83 @@
+// Copyright (C) 2017 go-nebulas authors
+//
+// This file is part of the go-nebulas library.
+//
+// the go-nebulas library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// the go-nebulas library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with the go-nebulas library.  If not, see <http://www.gnu.org/licenses/>.
+//
+
+package core
+
+import (
+	"math"
+	"math/bits"
+
+	"github.com/alexlisong/go-nebulas/util/byteutils"
+)
+
+// PowNonce return the proof-of-work stamp of tx, 0 if not attached
+func (tx *Transaction) PowNonce() uint64 {
+	return tx.powNonce
+}
+
+// AttachPoW grind the pow nonce until the tx hash has difficulty leading zero bits,
+// the nonce is hashed so the tx must be signed after, the work is 2^difficulty hashes on average.
+func (tx *Transaction) AttachPoW(difficulty uint8) error {
+	if tx.frozen {
+		return ErrTransactionFrozen
+	}
+	tx.cachedHash = nil
+	for nonce := uint64(1); ; nonce++ {
+		tx.powNonce = nonce
+		hash, err := tx.calHash()
+		if err != nil {
+			tx.powNonce = 0
+			return err
+		}
+		if leadingZeroBits(hash) >= int(difficulty) {
+			tx.rawBytes = nil
+			tx.cacheHash(hash)
+			return nil
+		}
+		if nonce == math.MaxUint64 {
+			tx.powNonce = 0
+			return ErrNonceOverflow
+		}
+	}
+}
+
+// VerifyPoW check the tx hash with its pow nonce has difficulty leading zero bits.
+func (tx *Transaction) VerifyPoW(difficulty uint8) bool {
+	if difficulty == 0 {
+		return true
+	}
+	if tx.powNonce == 0 {
+		return false
+	}
+	hash, err := tx.calHash()
+	if err != nil {
+		return false
+	}
+	return leadingZeroBits(hash) >= int(difficulty)
+}
+
+func leadingZeroBits(hash byteutils.Hash) int {
+	n := 0
+	for _, b := range hash {
+		if b != 0 {
+			return n + bits.LeadingZeros8(b)
+		}
+		n += 8
+	}
+	return n
+}
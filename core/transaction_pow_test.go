@@ -0,0 +1,98 @@
+// Copyright (C) 2017 go-nebulas authors
+//
+// This file is part of the go-nebulas library.
+//
+// the go-nebulas library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// the go-nebulas library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with the go-nebulas library.  If not, see <http://www.gnu.org/licenses/>.
+//
+
+package core
+
+import (
+	"testing"
+
+	"github.com/gogo/protobuf/proto"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTransaction_PoW(t *testing.T) {
+	tx := mockNormalTransaction(1, 1)
+	assert.True(t, tx.VerifyPoW(0))
+	assert.False(t, tx.VerifyPoW(8))
+	hash, err := tx.calHash()
+	assert.Nil(t, err)
+
+	assert.Nil(t, tx.AttachPoW(12))
+	assert.NotEqual(t, uint64(0), tx.PowNonce())
+	assert.True(t, tx.VerifyPoW(12))
+	assert.Equal(t, TxHashV2, tx.HashVersion())
+	// pow nonce is hashed
+	stamped, err := tx.calHash()
+	assert.Nil(t, err)
+	assert.NotEqual(t, hash, stamped)
+	assert.True(t, leadingZeroBits(stamped) >= 12)
+
+	// the stamp survives signing and serialization
+	assert.Nil(t, signTx(tx))
+	pbTx, err := tx.ToProto()
+	assert.Nil(t, err)
+	data, err := proto.Marshal(pbTx)
+	assert.Nil(t, err)
+	decoded, err := UnmarshalTransaction(data)
+	assert.Nil(t, err)
+	assert.Equal(t, tx.PowNonce(), decoded.PowNonce())
+	assert.True(t, decoded.VerifyPoW(12))
+	assert.Nil(t, decoded.VerifyIntegrity(1))
+
+	// changing any hashed field breaks the stamp
+	decoded.nonce++
+	assert.False(t, decoded.VerifyPoW(12))
+
+	// the pow nonce can't be moved into the validity window, nor back out of it.
+	for _, move := range []func(tx *Transaction){
+		func(tx *Transaction) { tx.validFrom, tx.powNonce = int64(tx.powNonce), 0 },
+		func(tx *Transaction) { tx.deadline, tx.powNonce = int64(tx.powNonce), 0 },
+	} {
+		moved := tx.Clone()
+		move(moved)
+		assert.Equal(t, ErrInvalidTransactionHash, moved.VerifyIntegrity(1))
+
+		signed := tx.Clone()
+		signed.Thaw()
+		move(signed)
+		assert.Nil(t, signTx(signed))
+		signed.validFrom, signed.deadline, signed.powNonce = 0, 0, tx.powNonce
+		assert.Equal(t, ErrInvalidTransactionHash, signed.VerifyIntegrity(1))
+	}
+
+	// the encodings of canonical signing flows keep the field of each value too.
+	canonical, err := tx.CanonicalJSON()
+	assert.Nil(t, err)
+	elements, err := tx.ToFieldElements()
+	assert.Nil(t, err)
+	moved := tx.Clone()
+	moved.validFrom, moved.powNonce = int64(tx.powNonce), 0
+	movedJSON, err := moved.CanonicalJSON()
+	assert.Nil(t, err)
+	assert.NotEqual(t, canonical, movedJSON)
+	movedElements, err := moved.ToFieldElements()
+	assert.Nil(t, err)
+	assert.NotEqual(t, elements, movedElements)
+}
+
+func TestLeadingZeroBits(t *testing.T) {
+	assert.Equal(t, 0, leadingZeroBits([]byte{0x80}))
+	assert.Equal(t, 7, leadingZeroBits([]byte{0x01, 0xff}))
+	assert.Equal(t, 12, leadingZeroBits([]byte{0x00, 0x0f}))
+	assert.Equal(t, 16, leadingZeroBits([]byte{0x00, 0x00}))
+}
@@ -0,0 +1,64 @@
+// Copyright (C) 2017 go-nebulas authors
+//
+// This file is part of the go-nebulas library.
+//
+// the go-nebulas library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// the go-nebulas library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with the go-nebulas library.  If not, see <http://www.gnu.org/licenses/>.
+//
+
+package core
+
+// TxPredicate reports whether a tx matches a filter.
+type TxPredicate func(*Transaction) bool
+
+// And return a predicate matching txs matched by all the preds, it matches every tx when preds is empty.
+func And(preds ...TxPredicate) TxPredicate {
+	return func(tx *Transaction) bool {
+		for _, pred := range preds {
+			if !pred(tx) {
+				return false
+			}
+		}
+		return true
+	}
+}
+
+// Or return a predicate matching txs matched by any of the preds, it matches no tx when preds is empty.
+func Or(preds ...TxPredicate) TxPredicate {
+	return func(tx *Transaction) bool {
+		for _, pred := range preds {
+			if pred(tx) {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// Not return a predicate matching txs not matched by pred.
+func Not(pred TxPredicate) TxPredicate {
+	return func(tx *Transaction) bool {
+		return !pred(tx)
+	}
+}
+
+// Where return txs matched by pred in order.
+func (txs Transactions) Where(pred TxPredicate) Transactions {
+	var matched Transactions
+	for _, tx := range txs {
+		if pred(tx) {
+			matched = append(matched, tx)
+		}
+	}
+	return matched
+}
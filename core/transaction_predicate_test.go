@@ -0,0 +1,53 @@
+// Copyright (C) 2017 go-nebulas authors
+//
+// This file is part of the go-nebulas library.
+//
+// the go-nebulas library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// the go-nebulas library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with the go-nebulas library.  If not, see <http://www.gnu.org/licenses/>.
+//
+
+package core
+
+import (
+	"testing"
+	"time"
+
+	"github.com/alexlisong/go-nebulas/util"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTransactions_Where(t *testing.T) {
+	now := time.Now().Unix()
+	from := mockAddress()
+	newTx := func(nonce, price uint64, age int64) *Transaction {
+		tx := mockPricedTransaction(from, nonce, price)
+		tx.timestamp = now - age
+		return tx
+	}
+	txs := Transactions{
+		newTx(1, 2000000, 10),
+		newTx(2, 2000000, 3600),
+		newTx(3, 1000000, 10),
+		newTx(4, 1000000, 3600),
+	}
+	minPrice, _ := util.NewUint128FromInt(1500000)
+	highFee := func(tx *Transaction) bool { return tx.gasPrice.Cmp(minPrice) >= 0 }
+	young := func(tx *Transaction) bool { return now-tx.timestamp < 60 }
+
+	assert.Equal(t, []uint64{1}, txNonces(txs.Where(And(highFee, young))))
+	assert.Equal(t, []uint64{1, 2, 3}, txNonces(txs.Where(Or(highFee, young))))
+	assert.Equal(t, []uint64{4}, txNonces(txs.Where(Not(Or(highFee, young)))))
+	assert.Equal(t, []uint64{2}, txNonces(txs.Where(And(highFee, Not(young)))))
+	assert.Equal(t, []uint64{1, 2, 3, 4}, txNonces(txs.Where(And())))
+	assert.Nil(t, txs.Where(Or()))
+}
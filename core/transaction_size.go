@@ -0,0 +1,208 @@
+// Copyright (C) 2017 go-nebulas authors
+//
+// This file is part of the go-nebulas library.
+//
+// the go-nebulas library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// the go-nebulas library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with the go-nebulas library.  If not, see <http://www.gnu.org/licenses/>.
+//
+
+package core
+
+import (
+	"math/big"
+	"unsafe"
+
+	"github.com/alexlisong/go-nebulas/core/pb"
+	"github.com/alexlisong/go-nebulas/util"
+	"github.com/gogo/protobuf/proto"
+)
+
+// uint128Length is the length of a fixed size serialized util.Uint128
+const uint128Length = 16
+
+// BLSAggregateSignatureLength is the length of a BLS12-381 aggregate signature in G2, compressed.
+const BLSAggregateSignatureLength = 96
+
+// heap cost of the fixed size parts of a tx
+var (
+	txStructBytes     = int(unsafe.Sizeof(Transaction{}))
+	addressBytes      = int(unsafe.Sizeof(Address{})) + AddressLength
+	uint128Bytes      = int(unsafe.Sizeof(util.Uint128{})) + int(unsafe.Sizeof(big.Int{})) + uint128Length
+	dataStructBytes   = int(unsafe.Sizeof(corepb.Data{}))
+	stringHeaderBytes = int(unsafe.Sizeof(""))
+	pointerBytes      = int(unsafe.Sizeof(&Transaction{}))
+)
+
+// Size return the length of the serialized tx.
+func (tx *Transaction) Size() (int, error) {
+	msg, err := tx.ToProto()
+	if err != nil {
+		return 0, err
+	}
+	return proto.Size(msg), nil
+}
+
+// EstimatedSize return the serialized length of the tx from the field widths, without marshalling.
+// it's equal to Size for txs with valid value, gasPrice and gasLimit, and never less than it:
+// tags of field numbers up to 15 take one byte, the others two, and zero fields are skipped as in proto3.
+func (tx *Transaction) EstimatedSize() int {
+	size := bytesFieldSize(len(tx.hash))
+	if tx.from != nil {
+		size += bytesFieldSize(len(tx.from.address))
+	}
+	if tx.to != nil {
+		size += bytesFieldSize(len(tx.to.address))
+	}
+	size += bytesFieldSize(uint128Length) // value
+	size += varintFieldSize(tx.nonce)
+	size += varintFieldSize(uint64(tx.timestamp))
+	if tx.data != nil {
+		dataSize := bytesFieldSize(len(tx.data.Type)) + bytesFieldSize(len(tx.data.Payload))
+		size += 1 + proto.SizeVarint(uint64(dataSize)) + dataSize
+	}
+	size += varintFieldSize(uint64(tx.chainID))
+	size += bytesFieldSize(uint128Length) // gasPrice
+	size += bytesFieldSize(uint128Length) // gasLimit
+	size += varintFieldSize(uint64(tx.alg))
+	size += bytesFieldSize(len(tx.sign))
+	size += bytesFieldSize(len(tx.salt))
+	if tx.tipRecipient != nil {
+		size += bytesFieldSize(len(tx.tipRecipient.address))
+	}
+	size += bytesFieldSize(len(tx.ephemeralPubKey))
+	size += highVarintFieldSize(tx.powNonce)
+	size += highVarintFieldSize(uint64(tx.hashAlg))
+	size += highVarintFieldSize(uint64(tx.validFrom))
+	size += highVarintFieldSize(uint64(tx.deadline))
+	size += highBytesFieldSize(len(tx.forkMarker))
+	return size
+}
+
+func bytesFieldSize(n int) int {
+	if n == 0 {
+		return 0
+	}
+	return 1 + proto.SizeVarint(uint64(n)) + n
+}
+
+func varintFieldSize(v uint64) int {
+	if v == 0 {
+		return 0
+	}
+	return 1 + proto.SizeVarint(v)
+}
+
+// highBytesFieldSize is bytesFieldSize of field numbers from 16 to 2047.
+func highBytesFieldSize(n int) int {
+	if n == 0 {
+		return 0
+	}
+	return 2 + proto.SizeVarint(uint64(n)) + n
+}
+
+// highVarintFieldSize is varintFieldSize of field numbers from 16 to 2047, whose tags take two bytes.
+func highVarintFieldSize(v uint64) int {
+	if v == 0 {
+		return 0
+	}
+	return 2 + proto.SizeVarint(v)
+}
+
+// FitsBlockSize check the sum of Size of txs is not greater than maxBytes, the sum is returned too.
+// it stops at the first tx over the limit or failing to serialize, so the sum is only the total when it fits.
+func (txs Transactions) FitsBlockSize(maxBytes int) (bool, int) {
+	total := 0
+	for _, tx := range txs {
+		size, err := tx.Size()
+		if err != nil {
+			return false, total
+		}
+		total += size
+		if total > maxBytes {
+			return false, total
+		}
+	}
+	return true, total
+}
+
+// ProjectedChainBytes return the bytes txs would add to the chain with a receipt of avgReceiptSize per tx,
+// txs are counted by EstimatedSize so the projection doesn't fall short of the serialized sizes.
+func (txs Transactions) ProjectedChainBytes(avgReceiptSize int) int {
+	total := 0
+	for _, tx := range txs {
+		if tx != nil {
+			total += tx.EstimatedSize() + avgReceiptSize
+		}
+	}
+	return total
+}
+
+// AggregationSavings return the sign bytes of the signed txs stored one by one,
+// and the bytes if they were replaced by one BLS aggregate signature, which doesn't grow with the count.
+func (txs Transactions) AggregationSavings() (individualBytes, aggregatedBytes int) {
+	for _, tx := range txs {
+		if tx != nil && len(tx.sign) > 0 {
+			individualBytes += len(tx.sign)
+			aggregatedBytes = BLSAggregateSignatureLength
+		}
+	}
+	return individualBytes, aggregatedBytes
+}
+
+// ApproxMemoryBytes return the approximate heap bytes held by the txs,
+// counting struct sizes and slice capacities but not allocator overhead.
+func (txs Transactions) ApproxMemoryBytes() int {
+	size := len(txs) * pointerBytes
+	for _, tx := range txs {
+		if tx != nil {
+			size += tx.approxMemoryBytes()
+		}
+	}
+	return size
+}
+
+func (tx *Transaction) approxMemoryBytes() int {
+	size := txStructBytes + 3*uint128Bytes
+	for _, addr := range []*Address{tx.from, tx.to, tx.tipRecipient, tx.builder} {
+		if addr != nil {
+			size += addressBytes
+		}
+	}
+	if tx.data != nil {
+		size += dataStructBytes + len(tx.data.Type) + cap(tx.data.Payload)
+	}
+	size += cap(tx.hash) + cap(tx.sign) + cap(tx.salt) + cap(tx.ephemeralPubKey) + cap(tx.forkMarker) + cap(tx.replacesHash)
+	for k, v := range tx.annotations {
+		size += 2*stringHeaderBytes + len(k) + len(v)
+	}
+	return size
+}
+
+// PreimageSize return the count of bytes calHash writes into the sha3-256 hasher, computed from the field sizes.
+func (tx *Transaction) PreimageSize() int {
+	// value, gasPrice, gasLimit, nonce, timestamp and chainID
+	size := len(txHashDomain) + 3*uint128Length + 8 + 8 + 4
+	if tx.from != nil {
+		size += len(tx.from.address)
+	}
+	if tx.to != nil {
+		size += len(tx.to.address)
+	}
+	if tx.data != nil {
+		size += proto.Size(tx.data)
+	}
+	for _, field := range tx.optionalHashFields() {
+		size += 1 + 4 + len(field.value)
+	}
+	return size
+}
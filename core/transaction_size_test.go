@@ -0,0 +1,186 @@
+// Copyright (C) 2017 go-nebulas authors
+//
+// This file is part of the go-nebulas library.
+//
+// the go-nebulas library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// the go-nebulas library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with the go-nebulas library.  If not, see <http://www.gnu.org/licenses/>.
+//
+
+package core
+
+import (
+	"testing"
+	"time"
+
+	"github.com/gogo/protobuf/proto"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTransaction_EstimatedSize(t *testing.T) {
+	contract := mockCallTransaction(1, 1, "totalSupply", "")
+	assert.Nil(t, signTx(contract))
+
+	tipped := mockNormalTransaction(1, 2)
+	tipped.SetTipRecipient(mockAddress())
+	tipped.SetReplaySalt(mockReplaySalt())
+	assert.Nil(t, signTx(tipped))
+
+	extended := mockNormalTransaction(1, 3)
+	assert.Nil(t, extended.SetHashAlgorithm(TxHashPoseidon))
+	assert.Nil(t, extended.SetValidityWindow(time.Unix(1500000000, 0), time.Unix(1600000000, 0)))
+	extended.SetForkMarker([]byte("fork-a"))
+	assert.Nil(t, extended.AttachPoW(2))
+	assert.Nil(t, signTx(extended))
+
+	tests := []struct {
+		name string
+		tx   *Transaction
+	}{
+		{"unsigned", mockNormalTransaction(1, 0)},
+		{"signed", mockSignedTransactions(t, 1)[0]},
+		{"deploy", mockDeployTransaction(1, 1)},
+		{"call", contract},
+		{"optional fields", tipped},
+		{"high field numbers", extended},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			size, err := tt.tx.Size()
+			assert.Nil(t, err)
+			assert.Equal(t, size, tt.tx.EstimatedSize())
+		})
+	}
+}
+
+func TestTransactions_ApproxMemoryBytes(t *testing.T) {
+	assert.Equal(t, 0, Transactions{}.ApproxMemoryBytes())
+
+	txs := mockSignedTransactions(t, 10)
+	one := txs[:1].ApproxMemoryBytes()
+	assert.True(t, one > txs[0].EstimatedSize())
+	assert.Equal(t, 10*one, txs.ApproxMemoryBytes())
+
+	// payload and annotations are counted
+	deploy := Transactions{mockDeployTransaction(1, 1)}
+	assert.True(t, deploy.ApproxMemoryBytes() > one+len(deploy[0].Data())-len(txs[0].sign))
+	txs[0].SetAnnotation("source", "rpc")
+	assert.Equal(t, one+2*stringHeaderBytes+len("source")+len("rpc"), txs[:1].ApproxMemoryBytes())
+}
+
+func TestTransactions_FitsBlockSize(t *testing.T) {
+	txs := mockSignedTransactions(t, 3)
+	total := 0
+	for _, tx := range txs {
+		size, err := tx.Size()
+		assert.Nil(t, err)
+		total += size
+	}
+	first, _ := txs[0].Size()
+
+	tests := []struct {
+		name     string
+		maxBytes int
+		fits     bool
+		size     int
+	}{
+		{"under", total + 1, true, total},
+		{"at", total, true, total},
+		{"over", total - 1, false, total},
+		{"first over", first - 1, false, first},
+		{"empty limit", 0, false, first},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fits, size := txs.FitsBlockSize(tt.maxBytes)
+			assert.Equal(t, tt.fits, fits)
+			assert.Equal(t, tt.size, size)
+		})
+	}
+
+	fits, size := Transactions{}.FitsBlockSize(0)
+	assert.True(t, fits)
+	assert.Equal(t, 0, size)
+}
+
+func TestTransactions_ProjectedChainBytes(t *testing.T) {
+	txs := mockSignedTransactions(t, 4)
+	receipts := [][]byte{
+		make([]byte, 90),
+		make([]byte, 110),
+		make([]byte, 100),
+		make([]byte, 100),
+	}
+
+	actual, receiptBytes := 0, 0
+	for i, tx := range txs {
+		size, err := tx.Size()
+		assert.Nil(t, err)
+		actual += size + len(receipts[i])
+		receiptBytes += len(receipts[i])
+	}
+	avgReceiptSize := receiptBytes / len(receipts)
+	assert.Equal(t, actual, txs.ProjectedChainBytes(avgReceiptSize))
+	assert.Equal(t, actual-receiptBytes, txs.ProjectedChainBytes(0))
+	assert.Equal(t, 0, Transactions{}.ProjectedChainBytes(avgReceiptSize))
+}
+
+func TestTransactions_AggregationSavings(t *testing.T) {
+	txs := mockSignedTransactions(t, 10)
+	signLength := len(txs[0].sign)
+	for n := 1; n <= len(txs); n++ {
+		individual, aggregated := txs[:n].AggregationSavings()
+		assert.Equal(t, n*signLength, individual)
+		assert.Equal(t, BLSAggregateSignatureLength, aggregated)
+	}
+
+	// unsigned txs have nothing to aggregate
+	individual, aggregated := Transactions{mockNormalTransaction(1, 1), nil}.AggregationSavings()
+	assert.Equal(t, 0, individual)
+	assert.Equal(t, 0, aggregated)
+}
+
+func TestTransaction_PreimageSize(t *testing.T) {
+	tx := mockCallTransaction(1, 1, "totalSupply", "")
+	data, err := proto.Marshal(tx.data)
+	assert.Nil(t, err)
+	assert.Equal(t, 2*AddressLength+3*16+8+8+4+len(data), tx.PreimageSize())
+
+	extended := tx.Clone()
+	extended.SetTipRecipient(mockAddress())
+	assert.Nil(t, extended.SetReplaySalt(mockReplaySalt()))
+	assert.Nil(t, extended.SetValidityWindow(time.Unix(1500000000, 0), time.Time{}))
+	extended.SetForkMarker([]byte("fork-a"))
+	// each optional field is prefixed by its tag and length.
+	assert.Equal(t, tx.PreimageSize()+4*(1+4)+AddressLength+ReplaySaltLength+8+len("fork-a"), extended.PreimageSize())
+
+	SetTxHashDomain([]byte("subchain"))
+	assert.Equal(t, 2*AddressLength+3*16+8+8+4+len(data)+len("subchain"), tx.PreimageSize())
+	SetTxHashDomain(nil)
+}
+
+func TestTransaction_VerifyPreimageSize(t *testing.T) {
+	defer func(max int) { MaxTransactionPreimageSize = max }(MaxTransactionPreimageSize)
+
+	tx := mockNormalTransaction(1, 1)
+	assert.Nil(t, signTx(tx))
+	MaxTransactionPreimageSize = tx.PreimageSize()
+	assert.Nil(t, tx.VerifyIntegrity(1))
+	MaxTransactionPreimageSize = tx.PreimageSize() - 1
+	assert.Equal(t, ErrPreimageTooLarge, tx.VerifyIntegrity(1))
+
+	// a payload over the limit is rejected before it's hashed
+	MaxTransactionPreimageSize = MaxDataPayLoadLength + 1024
+	huge := mockNormalTransaction(1, 1)
+	huge.data.Payload = make([]byte, MaxTransactionPreimageSize)
+	assert.Equal(t, ErrPreimageTooLarge, huge.VerifyIntegrity(1))
+}
@@ -0,0 +1,62 @@
+// Copyright (C) 2017 go-nebulas authors
+//
+// This file is part of the go-nebulas library.
+//
+// the go-nebulas library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// the go-nebulas library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with the go-nebulas library.  If not, see <http://www.gnu.org/licenses/>.
+//
+
+package core
+
+import (
+	"github.com/alexlisong/go-nebulas/util"
+)
+
+// StateDelta is the account changes made by a simple transfer when it's executed successfully.
+// when From and To are the same account, only the fee is taken from its balance.
+type StateDelta struct {
+	From      *Address
+	To        *Address
+	FromDebit *util.Uint128 // value + fee, subtracted from the balance of From
+	ToCredit  *util.Uint128 // value, added to the balance of To
+	Fee       *util.Uint128 // gasPrice * intrinsic gas, paid to the block producer
+	FromNonce uint64        // nonce of From after the tx
+}
+
+// StateDelta return the account changes of tx for optimistic balance updates of light clients,
+// only simple transfers are supported, other txs need the VM to know their changes.
+func (tx *Transaction) StateDelta() (*StateDelta, error) {
+	if !tx.IsSimpleTransfer() {
+		return nil, ErrStateDeltaNeedsExecution
+	}
+	gas, err := tx.IntrinsicGas()
+	if err != nil {
+		return nil, err
+	}
+	fee, err := tx.gasPrice.Mul(gas)
+	if err != nil {
+		return nil, err
+	}
+	debit, err := tx.value.Add(fee)
+	if err != nil {
+		return nil, err
+	}
+	return &StateDelta{
+		From:      tx.from,
+		To:        tx.to,
+		FromDebit: debit,
+		ToCredit:  tx.value,
+		Fee:       fee,
+		FromNonce: tx.nonce,
+	}, nil
+}
@@ -0,0 +1,62 @@
+// Copyright (C) 2017 go-nebulas authors
+//
+// This file is part of the go-nebulas library.
+//
+// the go-nebulas library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// the go-nebulas library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with the go-nebulas library.  If not, see <http://www.gnu.org/licenses/>.
+//
+
+package core
+
+import (
+	"testing"
+
+	"github.com/alexlisong/go-nebulas/util"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTransaction_StateDelta(t *testing.T) {
+	from, to := mockAddress(), mockAddress()
+	value, _ := util.NewUint128FromInt(1000000)
+	gasPrice, _ := util.NewUint128FromInt(2)
+	gasLimit, _ := util.NewUint128FromInt(200000)
+	tx, err := NewTransaction(1, from, to, value, 7, TxPayloadBinaryType, nil, gasPrice, gasLimit)
+	assert.Nil(t, err)
+
+	delta, err := tx.StateDelta()
+	assert.Nil(t, err)
+	assert.Equal(t, from, delta.From)
+	assert.Equal(t, to, delta.To)
+	// intrinsic gas of a plain transfer is the 20000 tx base gas
+	assert.Equal(t, "40000", delta.Fee.String())
+	assert.Equal(t, "1040000", delta.FromDebit.String())
+	assert.Equal(t, "1000000", delta.ToCredit.String())
+	assert.Equal(t, uint64(7), delta.FromNonce)
+}
+
+func TestTransaction_StateDeltaNeedsExecution(t *testing.T) {
+	tests := []struct {
+		name string
+		tx   *Transaction
+	}{
+		{"call", mockCallTransaction(1, 1, "transfer", "")},
+		{"deploy", mockDeployTransaction(1, 1)},
+		{"binary with data", mockTransaction(1, 1, TxPayloadBinaryType, []byte("data"))},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := tt.tx.StateDelta()
+			assert.Equal(t, ErrStateDeltaNeedsExecution, err)
+		})
+	}
+}
@@ -19,17 +19,26 @@
 package core
 
 import (
+	"bytes"
+	"encoding/hex"
 	"encoding/json"
 	"fmt"
+	"math"
+	"math/big"
 	"reflect"
+	"strings"
+	"sync"
 	"testing"
 	"time"
 
 	"github.com/gogo/protobuf/proto"
+	"github.com/alexlisong/go-nebulas/common/trie"
 	"github.com/alexlisong/go-nebulas/core/pb"
 	"github.com/alexlisong/go-nebulas/crypto"
 	"github.com/alexlisong/go-nebulas/crypto/hash"
 	"github.com/alexlisong/go-nebulas/crypto/keystore"
+	"github.com/alexlisong/go-nebulas/crypto/keystore/secp256k1"
+	"github.com/alexlisong/go-nebulas/storage"
 	"github.com/alexlisong/go-nebulas/util"
 	"github.com/alexlisong/go-nebulas/util/byteutils"
 	"github.com/stretchr/testify/assert"
@@ -67,6 +76,64 @@ func mockTransaction(chainID uint32, nonce uint64, payloadType string, payload [
 	return tx
 }
 
+// mockSenderTransaction return a normal tx of nonce sent by from.
+func mockSenderTransaction(from *Address, nonce uint64) *Transaction {
+	tx := mockNormalTransaction(1, nonce)
+	tx.from = from
+	return tx
+}
+
+// mockTransfer return a normal tx of nonce sent by from to to.
+func mockTransfer(from, to *Address, nonce uint64) *Transaction {
+	tx := mockSenderTransaction(from, nonce)
+	tx.to = to
+	return tx
+}
+
+// mockPayloadTransaction return a tx of nonce 1 sending value from from to to with payload.
+func mockPayloadTransaction(from, to *Address, value uint64, payloadType string, payload []byte) *Transaction {
+	tx, _ := NewTransaction(1, from, to, util.NewUint128FromUint(value), 1, payloadType, payload, TransactionGasPrice, TransactionMaxGas)
+	return tx
+}
+
+// mockValueTransaction return a binary tx of nonce sending value from from to a random address.
+func mockValueTransaction(from *Address, nonce, value uint64) *Transaction {
+	tx := mockPayloadTransaction(from, mockAddress(), value, TxPayloadBinaryType, nil)
+	tx.nonce = nonce
+	return tx
+}
+
+// mockPricedTransaction return a binary tx of nonce sent by from at gas price.
+func mockPricedTransaction(from *Address, nonce, price uint64) *Transaction {
+	tx := mockValueTransaction(from, nonce, 0)
+	tx.gasPrice = util.NewUint128FromUint(price)
+	return tx
+}
+
+// txNonces return the nonces of txs in order.
+func txNonces(txs Transactions) []uint64 {
+	var nonces []uint64
+	for _, tx := range txs {
+		nonces = append(nonces, tx.nonce)
+	}
+	return nonces
+}
+
+func signTx(tx *Transaction) error {
+	key, err := keystore.DefaultKS.GetUnlocked(tx.from.String())
+	if err != nil {
+		return err
+	}
+	signature, err := crypto.NewSignature(keystore.SECP256K1)
+	if err != nil {
+		return err
+	}
+	if err := signature.InitSign(key.(keystore.PrivateKey)); err != nil {
+		return err
+	}
+	return tx.Sign(signature)
+}
+
 func TestTransaction(t *testing.T) {
 	type fields struct {
 		hash      byteutils.Hash
@@ -767,3 +834,1893 @@ func TestDeployAndCall(t *testing.T) {
 func Test1(t *testing.T) {
 	fmt.Println(len(hash.Sha3256([]byte("abc"))))
 }
+
+func TestTransaction_EstimateConfirmationBlocks(t *testing.T) {
+	histogram := map[string]int{
+		"1000000": 3000,
+		"2000000": 1500,
+		"5000000": 200,
+		"invalid": 100000,
+	}
+	tests := []struct {
+		name     string
+		gasPrice int64
+		wanted   int
+	}{
+		{"highest fee", 5000000, 1},
+		{"medium fee", 2000000, 1},
+		{"default fee", 1000000, 2},
+		{"lowest fee", 1, 5},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tx := mockNormalTransaction(1, 1)
+			tx.gasPrice, _ = util.NewUint128FromInt(tt.gasPrice)
+			assert.Equal(t, tt.wanted, tx.EstimateConfirmationBlocks(histogram))
+		})
+	}
+}
+
+func TestTransaction_IsSpeedUpOf(t *testing.T) {
+	original := mockNormalTransaction(1, 10)
+	higherPrice, _ := TransactionGasPrice.Add(TransactionGasPrice)
+
+	speedUp, _ := NewTransaction(1, original.from, original.to, original.value, original.nonce, TxPayloadBinaryType, nil, higherPrice, original.gasLimit)
+	speedUp.SetReplacesHash(original.hash)
+	assert.True(t, speedUp.IsSpeedUpOf(original))
+
+	samePrice, _ := NewTransaction(1, original.from, original.to, original.value, original.nonce, TxPayloadBinaryType, nil, original.gasPrice, original.gasLimit)
+	assert.False(t, samePrice.IsSpeedUpOf(original))
+
+	otherNonce, _ := NewTransaction(1, original.from, original.to, original.value, original.nonce+1, TxPayloadBinaryType, nil, higherPrice, original.gasLimit)
+	assert.False(t, otherNonce.IsSpeedUpOf(original))
+
+	otherSender, _ := NewTransaction(1, mockAddress(), original.to, original.value, original.nonce, TxPayloadBinaryType, nil, higherPrice, original.gasLimit)
+	assert.False(t, otherSender.IsSpeedUpOf(original))
+	assert.False(t, speedUp.IsSpeedUpOf(nil))
+
+	// replacesHash is node-local, excluded from hash and serialization.
+	speedUp.SetReplacesHash([]byte("original"))
+	hash, err := speedUp.calHash()
+	assert.Nil(t, err)
+	speedUp.SetReplacesHash(nil)
+	hashWithout, err := speedUp.calHash()
+	assert.Nil(t, err)
+	assert.Equal(t, hash, hashWithout)
+
+	speedUp.SetReplacesHash([]byte("original"))
+	speedUp.alg = keystore.SECP256K1
+	msg, err := speedUp.ToProto()
+	assert.Nil(t, err)
+	ntx := new(Transaction)
+	assert.Nil(t, ntx.FromProto(msg))
+	assert.Nil(t, ntx.ReplacesHash())
+}
+
+func TestTransaction_VerifyWithHash(t *testing.T) {
+	tx := mockNormalTransaction(1, 1)
+	assert.Nil(t, signTx(tx))
+
+	hash, err := tx.calHash()
+	assert.Nil(t, err)
+	assert.Nil(t, tx.VerifyWithHash(hash, 1))
+	assert.Equal(t, ErrInvalidChainID, tx.VerifyWithHash(hash, 2))
+
+	assert.Equal(t, ErrHashMismatch, tx.VerifyWithHash(byteutils.Hash("mismatch"), 1))
+	assert.Equal(t, ErrHashMismatch, tx.VerifyWithHash(nil, 1))
+}
+
+func TestTransaction_AssertNonceSigned(t *testing.T) {
+	tx := mockNormalTransaction(1, 5)
+	assert.Nil(t, signTx(tx))
+	assert.Nil(t, tx.AssertNonceSigned(5))
+	assert.Equal(t, ErrNonceNotSigned, tx.AssertNonceSigned(6))
+
+	// swap the nonce but keep the original hash and signature.
+	swapped := tx.Clone()
+	swapped.nonce = 6
+	assert.Equal(t, ErrNonceNotSigned, swapped.AssertNonceSigned(6))
+	assert.Equal(t, ErrNonceNotSigned, swapped.AssertNonceSigned(5))
+
+	// rehashed with the swapped nonce, the old signature is not from the sender.
+	swapped.hash, _ = swapped.calHash()
+	assert.Equal(t, ErrInvalidTransactionSigner, swapped.AssertNonceSigned(6))
+}
+
+func TestTransaction_Annotations(t *testing.T) {
+	tx := mockNormalTransaction(1, 1)
+	assert.Nil(t, signTx(tx))
+	hash := tx.Hash()
+
+	_, ok := tx.GetAnnotation("peer")
+	assert.False(t, ok)
+	tx.SetAnnotation("peer", "QmPeer")
+	tx.SetAnnotation("receivedAt", "1516464510")
+	peer, ok := tx.GetAnnotation("peer")
+	assert.True(t, ok)
+	assert.Equal(t, "QmPeer", peer)
+
+	// annotations are not hashed.
+	wantedHash, err := tx.calHash()
+	assert.Nil(t, err)
+	assert.Equal(t, hash, wantedHash)
+
+	// annotations survive Clone.
+	ctx := tx.Clone()
+	peer, ok = ctx.GetAnnotation("peer")
+	assert.True(t, ok)
+	assert.Equal(t, "QmPeer", peer)
+	ctx.SetAnnotation("peer", "QmOther")
+	peer, _ = tx.GetAnnotation("peer")
+	assert.Equal(t, "QmPeer", peer)
+
+	// annotations are not serialized.
+	msg, err := tx.ToProto()
+	assert.Nil(t, err)
+	ir, err := proto.Marshal(msg)
+	assert.Nil(t, err)
+	pbTx := new(corepb.Transaction)
+	assert.Nil(t, proto.Unmarshal(ir, pbTx))
+	ntx := new(Transaction)
+	assert.Nil(t, ntx.FromProto(pbTx))
+	_, ok = ntx.GetAnnotation("peer")
+	assert.False(t, ok)
+	assert.Equal(t, hash, ntx.Hash())
+}
+
+func TestTransaction_FeeBumpOver(t *testing.T) {
+	original := mockNormalTransaction(1, 10)
+	// original fee: 10^6 * 5*10^10
+	bumpedPrice, _ := util.NewUint128FromInt(1100000)
+	smallPrice, _ := util.NewUint128FromInt(1050000)
+
+	sufficient, _ := NewTransaction(1, original.from, original.to, original.value, original.nonce, TxPayloadBinaryType, nil, bumpedPrice, original.gasLimit)
+	bump, err := sufficient.FeeBumpOver(original)
+	assert.Nil(t, err)
+	assert.Equal(t, "5000000000000000", bump.String())
+	assert.True(t, sufficient.MeetsMinBump(original, 10))
+	assert.False(t, sufficient.MeetsMinBump(original, 11))
+
+	insufficient, _ := NewTransaction(1, original.from, original.to, original.value, original.nonce, TxPayloadBinaryType, nil, smallPrice, original.gasLimit)
+	_, err = insufficient.FeeBumpOver(original)
+	assert.Nil(t, err)
+	assert.False(t, insufficient.MeetsMinBump(original, 10))
+
+	_, err = original.FeeBumpOver(sufficient)
+	assert.Equal(t, ErrInsufficientFeeBump, err)
+	assert.False(t, original.MeetsMinBump(sufficient, 0))
+
+	notReplacement, _ := NewTransaction(1, original.from, original.to, original.value, original.nonce+1, TxPayloadBinaryType, nil, bumpedPrice, original.gasLimit)
+	_, err = notReplacement.FeeBumpOver(original)
+	assert.Equal(t, ErrNotReplacementTransaction, err)
+	assert.False(t, notReplacement.MeetsMinBump(original, 0))
+}
+
+func TestTransaction_SignWithRawKey(t *testing.T) {
+	priv, _ := hex.DecodeString("a54a2b6ab5bbbc7eb73718e9f7b7c93b1dd2e7c6e0a1ea0ec3fd35e16fc2acb4")
+	pub, err := secp256k1.GetPublicKey(priv)
+	assert.Nil(t, err)
+	from, err := NewAddressFromPublicKey(pub)
+	assert.Nil(t, err)
+
+	tx := mockNormalTransaction(1, 0)
+	tx.from = from
+	assert.Nil(t, tx.SignWithRawKey(priv, keystore.SECP256K1))
+	assert.Equal(t, keystore.SECP256K1, tx.alg)
+	assert.Nil(t, tx.VerifyIntegrity(1))
+
+	// raw key is not cleared after signing.
+	assert.Equal(t, "a54a2b6ab5bbbc7eb73718e9f7b7c93b1dd2e7c6e0a1ea0ec3fd35e16fc2acb4", hex.EncodeToString(priv))
+
+	order, _ := hex.DecodeString("fffffffffffffffffffffffffffffffebaaedce6af48a03bbfd25e8cd0364141")
+	tests := []struct {
+		name string
+		priv []byte
+		alg  keystore.Algorithm
+		err  error
+	}{
+		{"short", priv[:31], keystore.SECP256K1, ErrInvalidPrivateKey},
+		{"zero", make([]byte, 32), keystore.SECP256K1, ErrInvalidPrivateKey},
+		{"order", order, keystore.SECP256K1, ErrInvalidPrivateKey},
+		{"alg", priv, keystore.Algorithm(0), crypto.ErrAlgorithmInvalid},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.err, tx.SignWithRawKey(tt.priv, tt.alg))
+		})
+	}
+}
+
+// mockSigner signs with a software key, or with signKey when set to mimic a misbehaving device.
+type mockSigner struct {
+	key     *secp256k1.PrivateKey
+	signKey *secp256k1.PrivateKey
+	err     error
+}
+
+func (s *mockSigner) Public() keystore.PublicKey {
+	return s.key.PublicKey()
+}
+
+func (s *mockSigner) Sign(hash byteutils.Hash) ([]byte, error) {
+	if s.err != nil {
+		return nil, s.err
+	}
+	if s.signKey != nil {
+		return s.signKey.Sign(hash)
+	}
+	return s.key.Sign(hash)
+}
+
+func TestTransaction_SignWithSigner(t *testing.T) {
+	signer := &mockSigner{key: secp256k1.GeneratePrivateKey()}
+	pub, err := signer.Public().Encoded()
+	assert.Nil(t, err)
+	from, err := NewAddressFromPublicKey(pub)
+	assert.Nil(t, err)
+
+	tx := mockNormalTransaction(1, 1)
+	assert.Nil(t, tx.SignWithSigner(signer, keystore.SECP256K1))
+	assert.True(t, from.Equals(tx.from))
+	assert.Equal(t, keystore.SECP256K1, tx.alg)
+	assert.Nil(t, tx.VerifyIntegrity(1))
+
+	tests := []struct {
+		name   string
+		signer Signer
+		alg    keystore.Algorithm
+		err    error
+	}{
+		{"nil", nil, keystore.SECP256K1, ErrNilArgument},
+		{"alg", signer, keystore.Algorithm(0), crypto.ErrAlgorithmInvalid},
+		{"device error", &mockSigner{key: signer.key, err: ErrInvalidSignature}, keystore.SECP256K1, ErrInvalidSignature},
+		{"other key", &mockSigner{key: signer.key, signKey: secp256k1.GeneratePrivateKey()}, keystore.SECP256K1, ErrInvalidTransactionSigner},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			unsigned := mockNormalTransaction(1, 2)
+			prevFrom := unsigned.from
+			assert.Equal(t, tt.err, unsigned.SignWithSigner(tt.signer, tt.alg))
+			assert.True(t, prevFrom.Equals(unsigned.from))
+			assert.Nil(t, unsigned.sign)
+		})
+	}
+}
+
+type mockGasEstimator struct {
+	gas   *util.Uint128
+	calls int
+}
+
+func (e *mockGasEstimator) EstimateGas(tx *Transaction) (*util.Uint128, error) {
+	e.calls++
+	return e.gas, nil
+}
+
+func TestTransaction_VerifyAndEstimate(t *testing.T) {
+	estimator := &mockGasEstimator{gas: util.NewUint128FromUint(25000)}
+
+	transfer := mockNormalTransaction(1, 1)
+	assert.Nil(t, signTx(transfer))
+	intrinsic, err := transfer.IntrinsicGas()
+	assert.Nil(t, err)
+	gas, err := transfer.VerifyAndEstimate(1, estimator)
+	assert.Nil(t, err)
+	assert.Equal(t, intrinsic.Uint64(), gas)
+	gas, err = transfer.VerifyAndEstimate(1, nil)
+	assert.Nil(t, err)
+	assert.Equal(t, intrinsic.Uint64(), gas)
+	assert.Equal(t, 0, estimator.calls)
+
+	call := mockCallTransaction(1, 2, "totalSupply", "")
+	assert.Nil(t, signTx(call))
+	gas, err = call.VerifyAndEstimate(1, estimator)
+	assert.Nil(t, err)
+	assert.Equal(t, uint64(25000), gas)
+	assert.Equal(t, 1, estimator.calls)
+	_, err = call.VerifyAndEstimate(1, nil)
+	assert.Equal(t, ErrNilArgument, err)
+
+	// invalid txs error before estimation.
+	_, err = call.VerifyAndEstimate(2, estimator)
+	assert.Equal(t, ErrInvalidChainID, err)
+	unsigned := mockCallTransaction(1, 3, "totalSupply", "")
+	_, err = unsigned.VerifyAndEstimate(1, estimator)
+	assert.NotNil(t, err)
+	assert.Equal(t, 1, estimator.calls)
+
+	// gas limits below the intrinsic gas are rejected before estimation.
+	underGas, _ := NewTransaction(1, call.from, call.to, util.NewUint128(), 4, TxPayloadCallType, call.data.Payload, TransactionGasPrice, MinGasCountPerTransaction)
+	assert.Nil(t, signTx(underGas))
+	_, err = underGas.VerifyAndEstimate(1, estimator)
+	assert.Equal(t, ErrGasLimitBelowIntrinsic, err)
+	assert.Equal(t, 1, estimator.calls)
+}
+
+func TestVerifyInclusion(t *testing.T) {
+	for _, n := range []int{1, 2, 5, 7, 8} {
+		txs := make(Transactions, n)
+		for i := range txs {
+			txs[i] = mockNormalTransaction(1, uint64(i+1))
+			assert.Nil(t, signTx(txs[i]))
+		}
+		root := txs.MerkleRoot()
+		for i, tx := range txs {
+			proof, err := txs.MerkleProof(i)
+			assert.Nil(t, err)
+			assert.True(t, VerifyInclusion(tx.hash, proof, i, root), "%d of %d", i, n)
+			if n > 1 {
+				assert.False(t, VerifyInclusion(tx.hash, proof, i^1, root))
+				assert.False(t, VerifyInclusion(tx.hash, proof[:len(proof)-1], i, root))
+			}
+			other := txs[(i+1)%n]
+			assert.Equal(t, n == 1, VerifyInclusion(other.hash, proof, i, root))
+		}
+		_, err := txs.MerkleProof(n)
+		assert.Equal(t, ErrInvalidArgument, err)
+	}
+
+	txs := make(Transactions, 5)
+	for i := range txs {
+		txs[i] = mockNormalTransaction(1, uint64(i+1))
+		assert.Nil(t, signTx(txs[i]))
+	}
+	root := txs.MerkleRoot()
+	// the 5th tx is promoted twice, so its proof is nil, nil and the root of the first 4.
+	proof, err := txs.MerkleProof(4)
+	assert.Nil(t, err)
+	assert.Equal(t, 3, len(proof))
+	assert.Nil(t, proof[0])
+	assert.Nil(t, proof[1])
+	assert.True(t, VerifyInclusion(txs[4].hash, proof, 4, root))
+
+	// forged sibling
+	proof, _ = txs.MerkleProof(2)
+	forged := make([]byteutils.Hash, len(proof))
+	copy(forged, proof)
+	forged[0] = hash.Sha3256([]byte{0x0}, []byte("forged"))
+	assert.False(t, VerifyInclusion(txs[2].hash, forged, 2, root))
+	// a right node can't claim to be promoted.
+	forged[0] = nil
+	assert.False(t, VerifyInclusion(txs[3].hash, forged, 3, root))
+	// an inner node can't be proven as a leaf.
+	inner := hash.Sha3256([]byte{0x1}, hash.Sha3256([]byte{0x0}, txs[0].hash), hash.Sha3256([]byte{0x0}, txs[1].hash))
+	proof, _ = txs.MerkleProof(0)
+	assert.False(t, VerifyInclusion(inner, proof[1:], 0, root))
+	assert.False(t, VerifyInclusion(txs[0].hash, proof, 0, nil))
+	assert.False(t, VerifyInclusion(txs[0].hash, proof, -1, root))
+}
+
+func TestTransaction_SupportedAlgorithms(t *testing.T) {
+	for _, alg := range crypto.SupportedAlgorithms() {
+		priv, err := crypto.NewPrivateKey(alg, nil)
+		assert.Nil(t, err)
+		pub, err := priv.PublicKey().Encoded()
+		assert.Nil(t, err)
+		from, err := NewAddressFromPublicKey(pub)
+		assert.Nil(t, err)
+
+		tx, _ := NewTransaction(1, from, mockAddress(), util.NewUint128FromUint(1), 1, TxPayloadBinaryType, nil, TransactionGasPrice, TransactionMaxGas)
+		signature, err := crypto.NewSignature(alg)
+		assert.Nil(t, err)
+		assert.Nil(t, signature.InitSign(priv))
+		assert.Nil(t, tx.Sign(signature))
+		assert.Equal(t, alg, tx.alg)
+		assert.Nil(t, tx.VerifyIntegrity(1))
+	}
+}
+
+func TestTransaction_UnsupportedAlgorithm(t *testing.T) {
+	tx := mockNormalTransaction(1, 1)
+	assert.Nil(t, signTx(tx))
+	assert.Nil(t, tx.VerifyIntegrity(1))
+
+	tx.alg = keystore.Algorithm(255)
+	assert.Equal(t, crypto.ErrAlgorithmInvalid, tx.VerifyIntegrity(1))
+}
+
+type mockBurnPolicy struct {
+	burns []*Address
+}
+
+func (p *mockBurnPolicy) CheckRecipient(addr *Address) error {
+	for _, v := range p.burns {
+		if v.Equals(addr) {
+			return ErrInvalidArgument
+		}
+	}
+	return nil
+}
+
+func TestTransaction_CheckRecipient(t *testing.T) {
+	tx := mockNormalTransaction(1, 1)
+	burn, _ := AddressParse("n1FF1nz6tarkDVwWQkMnnwFPuPKUaQTdptE")
+	policy := &mockBurnPolicy{burns: []*Address{burn}}
+
+	assert.Nil(t, tx.CheckRecipient(nil))
+	assert.Nil(t, tx.CheckRecipient(policy))
+
+	tx.to = burn
+	assert.Equal(t, ErrInvalidArgument, tx.CheckRecipient(policy))
+}
+
+func TestTransaction_MinReplacementFee(t *testing.T) {
+	tx, _ := NewTransaction(1, mockAddress(), mockAddress(), util.NewUint128(), 1, TxPayloadBinaryType, nil, util.NewUint128FromUint(1000001), util.NewUint128FromUint(20000))
+	assert.Nil(t, signTx(tx))
+	// fee = 20000020000
+	tests := []struct {
+		name    string
+		percent int
+		minFee  string
+	}{
+		{"no bump", 0, "20000020000"},
+		{"negative", -10, "20000020000"},
+		{"ten", 10, "22000022000"},
+		{"rounded up", 13, "22600022600"},
+		{"double", 100, "40000040000"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			minFee := tx.MinReplacementFee(tt.percent)
+			assert.Equal(t, tt.minFee, minFee.String())
+
+			replacement, err := tx.BuildReplacement(tt.percent)
+			assert.Nil(t, err)
+			assert.Nil(t, replacement.sign)
+			assert.Equal(t, tx.hash, replacement.ReplacesHash())
+			assert.True(t, replacement.IsSpeedUpOf(tx))
+			if tt.percent >= 0 {
+				assert.True(t, replacement.MeetsMinBump(tx, tt.percent))
+			}
+			fee, err := replacement.Fee()
+			assert.Nil(t, err)
+			assert.True(t, new(big.Int).SetBytes(fee.Bytes()).Cmp(minFee) >= 0)
+			// the next lower gas price doesn't pay the minimum
+			lower, _ := replacement.gasPrice.Sub(util.NewUint128FromUint(1))
+			lowerFee, _ := lower.Mul(replacement.gasLimit)
+			assert.True(t, tt.percent <= 0 || new(big.Int).SetBytes(lowerFee.Bytes()).Cmp(minFee) < 0)
+
+			assert.Nil(t, signTx(replacement))
+			assert.Nil(t, replacement.VerifyIntegrity(1))
+		})
+	}
+
+	// 21 * 101 / 100 is rounded up
+	odd, _ := NewTransaction(1, mockAddress(), mockAddress(), util.NewUint128(), 1, TxPayloadBinaryType, nil, util.NewUint128FromUint(3), util.NewUint128FromUint(7))
+	assert.Equal(t, "22", odd.MinReplacementFee(1).String())
+
+	maxed, _ := NewTransaction(1, mockAddress(), mockAddress(), util.NewUint128(), 1, TxPayloadBinaryType, nil, TransactionMaxGasPrice, util.NewUint128FromUint(20000))
+	_, err := maxed.BuildReplacement(10)
+	assert.Equal(t, ErrInvalidGasPrice, err)
+}
+
+func TestTransaction_SigningAuditHook(t *testing.T) {
+	type entry struct {
+		hash   byteutils.Hash
+		signer *Address
+		at     time.Time
+	}
+	var entries []entry
+	SetSigningAuditHook(func(tx *Transaction, signer *Address, at time.Time) {
+		entries = append(entries, entry{tx.Hash(), signer, at})
+	})
+	defer SetSigningAuditHook(nil)
+
+	before := time.Now()
+	tx := mockNormalTransaction(1, 1)
+	assert.Nil(t, signTx(tx))
+	seed, _ := hex.DecodeString("000102030405060708090a0b0c0d0e0f")
+	hdTx := mockNormalTransaction(1, 2)
+	assert.Nil(t, hdTx.SignWithHDPath(seed, "m/44'/2718'/0'/0/0", keystore.SECP256K1))
+
+	assert.Equal(t, 2, len(entries))
+	assert.Equal(t, tx.hash, entries[0].hash)
+	assert.True(t, tx.from.Equals(entries[0].signer))
+	assert.False(t, entries[0].at.Before(before))
+	assert.Equal(t, hdTx.hash, entries[1].hash)
+	assert.Equal(t, "n1b5jPG34CSJaPvCNdS2rJJygMtD5rteeFD", entries[1].signer.String())
+
+	// failed signs are not logged
+	assert.NotNil(t, mockNormalTransaction(1, 3).Sign(nil))
+	assert.Equal(t, 2, len(entries))
+
+	// a sign the signer can't be recovered from fails before the tx is changed.
+	unrecoverable := mockNormalTransaction(1, 3)
+	signature, _ := crypto.NewSignature(keystore.SECP256K1)
+	assert.NotNil(t, unrecoverable.Sign(&unrecoverableSignature{signature}))
+	assert.Equal(t, 2, len(entries))
+	assert.Nil(t, unrecoverable.hash)
+	assert.Nil(t, unrecoverable.sign)
+	assert.Equal(t, keystore.Algorithm(0), unrecoverable.alg)
+	assert.False(t, unrecoverable.frozen)
+	assert.Nil(t, unrecoverable.SetTipRecipient(mockAddress()))
+
+	SetSigningAuditHook(nil)
+	assert.Nil(t, signTx(mockNormalTransaction(1, 4)))
+	assert.Equal(t, 2, len(entries))
+}
+
+// unrecoverableSignature signs with a sign no public key can be recovered from.
+type unrecoverableSignature struct {
+	keystore.Signature
+}
+
+func (s *unrecoverableSignature) Sign(data []byte) ([]byte, error) {
+	return make([]byte, 65), nil
+}
+
+type mockCodeOracle struct {
+	contracts []*Address
+	err       error
+}
+
+func (o *mockCodeOracle) HasCode(addr *Address) (bool, error) {
+	if o.err != nil {
+		return false, o.err
+	}
+	for _, v := range o.contracts {
+		if v.Equals(addr) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func TestTransaction_ValidateCallTarget(t *testing.T) {
+	contract, _ := AddressParse("n1FkntVUMPAsESuCAAPK711omQk19JotBjM")
+	oracle := &mockCodeOracle{contracts: []*Address{contract}}
+
+	call := mockCallTransaction(1, 1, "totalSupply", "")
+	call.to = contract
+	assert.Nil(t, call.ValidateCallTarget(oracle))
+
+	call.to = mockAddress()
+	assert.Equal(t, ErrCallToNonContract, call.ValidateCallTarget(oracle))
+	assert.Nil(t, call.ValidateCallTarget(nil))
+	assert.Equal(t, ErrInvalidArgument, call.ValidateCallTarget(&mockCodeOracle{err: ErrInvalidArgument}))
+
+	// only calls are checked
+	assert.Nil(t, mockNormalTransaction(1, 1).ValidateCallTarget(oracle))
+	assert.Nil(t, mockDeployTransaction(1, 1).ValidateCallTarget(oracle))
+}
+
+func TestTransactions_NextNonceFor(t *testing.T) {
+	from := mockAddress()
+	other := mockAddress()
+
+	tests := []struct {
+		name    string
+		txs     Transactions
+		current uint64
+		want    uint64
+	}{
+		{"empty", nil, 3, 4},
+		{"contiguous", Transactions{mockSenderTransaction(from, 5), mockSenderTransaction(from, 4), mockSenderTransaction(from, 6)}, 3, 7},
+		{"gapped", Transactions{mockSenderTransaction(from, 4), mockSenderTransaction(from, 6), mockSenderTransaction(from, 7)}, 3, 5},
+		{"stale", Transactions{mockSenderTransaction(from, 2), mockSenderTransaction(from, 3)}, 3, 4},
+		{"other sender", Transactions{mockSenderTransaction(other, 4), mockSenderTransaction(from, 4), mockSenderTransaction(other, 5)}, 3, 5},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, tt.txs.NextNonceFor(from, tt.current))
+		})
+	}
+}
+
+func TestTransaction_SigningSummary(t *testing.T) {
+	from, _ := AddressParse("n1FF1nz6tarkDVwWQkMnnwFPuPKUaQTdptE")
+	to, _ := AddressParse("n1GmkKH6nBMw4rrjt16RrJ9WcgvKUtAZP1s")
+	value, _ := util.NewUint128FromString("1000000000000000000")
+	gasPrice, _ := util.NewUint128FromInt(1000000)
+	gasLimit, _ := util.NewUint128FromInt(20000)
+	tx, err := NewTransaction(100, from, to, value, 7, TxPayloadBinaryType, []byte("hello"), gasPrice, gasLimit)
+	assert.Nil(t, err)
+
+	want := "From: n1FF1nz6tarkDVwWQkMnnwFPuPKUaQTdptE\n" +
+		"To: n1GmkKH6nBMw4rrjt16RrJ9WcgvKUtAZP1s\n" +
+		"Amount: 1000000000000000000\n" +
+		"Fee: 20000000000\n" +
+		"Nonce: 7\n" +
+		"ChainID: 100\n" +
+		"Type: binary\n" +
+		"Data size: 5"
+	assert.Equal(t, want, tx.SigningSummary())
+
+	// signing does not change the summary.
+	tx.from = mockAddress()
+	before := tx.SigningSummary()
+	assert.Nil(t, signTx(tx))
+	assert.Equal(t, before, tx.SigningSummary())
+	assert.NotContains(t, tx.SigningSummary(), tx.sign.String())
+}
+
+func TestTransaction_Equals(t *testing.T) {
+	tx := mockNormalTransaction(1, 1)
+	assert.Nil(t, signTx(tx))
+	clone := tx.Clone()
+	clone.SetAnnotation("source", "rpc")
+	assert.True(t, tx.Equals(clone))
+	assert.False(t, tx.Equals(nil))
+
+	other := mockNormalTransaction(1, 2)
+	assert.Nil(t, signTx(other))
+	assert.False(t, tx.Equals(other))
+
+	// same hash, different content.
+	forged := tx.Clone()
+	forged.nonce = 2
+	assert.False(t, tx.Equals(forged))
+
+	// uncomputed hash falls back to full comparison.
+	unsigned := mockNormalTransaction(1, 1)
+	assert.False(t, tx.Equals(unsigned))
+}
+
+func BenchmarkTransaction_EqualsHashMismatch(b *testing.B) {
+	tx := mockNormalTransaction(1, 1)
+	signTx(tx)
+	other := mockNormalTransaction(1, 2)
+	signTx(other)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		tx.Equals(other)
+	}
+}
+
+func BenchmarkTransaction_EqualsNoHash(b *testing.B) {
+	tx := mockNormalTransaction(1, 1)
+	other := tx.Clone()
+	other.sign = []byte{1}
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		tx.Equals(other)
+	}
+}
+
+func TestTransactions_FitWithinGas(t *testing.T) {
+	var txs Transactions
+	for i, limit := range []int64{30000, 20000, 50000, 10000} {
+		gasLimit, _ := util.NewUint128FromInt(limit)
+		tx, _ := NewTransaction(1, mockAddress(), mockAddress(), util.NewUint128(), uint64(i+1), TxPayloadBinaryType, nil, TransactionGasPrice, gasLimit)
+		txs = append(txs, tx)
+	}
+
+	tests := []struct {
+		name     string
+		limit    int64
+		included int
+	}{
+		{"tight", 60000, 2},
+		{"exact", 50000, 2},
+		{"too small", 20000, 0},
+		{"generous", 1000000, 4},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			limit, _ := util.NewUint128FromInt(tt.limit)
+			included, excluded := txs.FitWithinGas(limit)
+			assert.Equal(t, tt.included, len(included))
+			assert.Equal(t, len(txs)-tt.included, len(excluded))
+			assert.Equal(t, txs, append(included, excluded...))
+		})
+	}
+}
+
+func TestTransaction_ValidateStoredHash(t *testing.T) {
+	tx := mockNormalTransaction(1, 1)
+	assert.Equal(t, ErrHashMismatch, tx.ValidateStoredHash())
+	assert.Nil(t, signTx(tx))
+	assert.Nil(t, tx.ValidateStoredHash())
+
+	altered := tx.Clone()
+	altered.hash = append(byteutils.Hash{}, tx.hash...)
+	altered.hash[0] ^= 0xff
+	assert.Equal(t, ErrHashMismatch, altered.ValidateStoredHash())
+
+	tampered := tx.Clone()
+	tampered.nonce = 2
+	assert.Equal(t, ErrHashMismatch, tampered.ValidateStoredHash())
+}
+
+func TestTransactions_ShortIDs(t *testing.T) {
+	var txs Transactions
+	for i := 1; i <= 10; i++ {
+		tx := mockNormalTransaction(1, uint64(i))
+		assert.Nil(t, signTx(tx))
+		txs = append(txs, tx)
+	}
+	ids := txs.ShortIDs()
+	assert.Equal(t, len(txs), len(ids))
+
+	seen := make(map[[8]byte]bool)
+	for i, tx := range txs {
+		assert.Equal(t, tx.ShortID(), ids[i])
+		assert.Equal(t, tx.ShortID(), tx.Clone().ShortID())
+		assert.True(t, bytes.Equal(tx.hash[:8], ids[i][:]))
+		assert.False(t, seen[ids[i]])
+		seen[ids[i]] = true
+	}
+}
+
+func TestTransaction_TipRecipient(t *testing.T) {
+	tx := mockNormalTransaction(1, 1)
+	assert.Nil(t, tx.TipRecipient())
+	defaultHash, _ := tx.calHash()
+
+	relay := mockAddress()
+	tx.SetTipRecipient(relay)
+	tipHash, _ := tx.calHash()
+	assert.NotEqual(t, defaultHash, tipHash)
+
+	assert.Nil(t, signTx(tx))
+	assert.Nil(t, tx.VerifyIntegrity(1))
+
+	pbTx, err := tx.ToProto()
+	assert.Nil(t, err)
+	ir, _ := proto.Marshal(pbTx)
+	msg := new(corepb.Transaction)
+	assert.Nil(t, proto.Unmarshal(ir, msg))
+	decoded := new(Transaction)
+	assert.Nil(t, decoded.FromProto(msg))
+	assert.True(t, relay.Equals(decoded.TipRecipient()))
+	assert.True(t, tx.Equals(decoded))
+	assert.Nil(t, decoded.VerifyIntegrity(1))
+
+	// changing tip recipient breaks hash.
+	decoded.SetTipRecipient(mockAddress())
+	assert.Equal(t, ErrInvalidTransactionHash, decoded.VerifyIntegrity(1))
+
+	// empty tip recipient is allowed.
+	plain := mockNormalTransaction(1, 2)
+	assert.Nil(t, signTx(plain))
+	pbTx, _ = plain.ToProto()
+	assert.Nil(t, pbTx.(*corepb.Transaction).TipRecipient)
+	decoded = new(Transaction)
+	assert.Nil(t, decoded.FromProto(pbTx))
+	assert.Nil(t, decoded.TipRecipient())
+	assert.Nil(t, decoded.VerifyIntegrity(1))
+
+	msg.TipRecipient = []byte{1, 2, 3}
+	assert.NotNil(t, new(Transaction).FromProto(msg))
+}
+
+func TestTransaction_ValidatePayload(t *testing.T) {
+	deploy, _ := NewDeployPayload("var a = 1;", SourceTypeJavaScript, "")
+	deployBytes, _ := deploy.ToBytes()
+	call, _ := NewCallPayload("transfer", "[\"n1FF1nz6tarkDVwWQkMnnwFPuPKUaQTdptE\"]")
+	callBytes, _ := call.ToBytes()
+
+	tests := []struct {
+		name        string
+		payloadType string
+		payload     []byte
+		err         error
+	}{
+		{"binary empty", TxPayloadBinaryType, nil, nil},
+		{"binary arbitrary", TxPayloadBinaryType, []byte{0xff, 0x00, 0x01}, nil},
+		{"deploy", TxPayloadDeployType, deployBytes, nil},
+		{"deploy malformed", TxPayloadDeployType, []byte("{"), ErrInvalidArgument},
+		{"deploy empty source", TxPayloadDeployType, []byte(`{"SourceType":"js","Source":""}`), ErrInvalidDeploySource},
+		{"deploy source type", TxPayloadDeployType, []byte(`{"SourceType":"go","Source":"var a = 1;"}`), ErrInvalidDeploySourceType},
+		{"call", TxPayloadCallType, callBytes, nil},
+		{"call malformed", TxPayloadCallType, []byte("call"), ErrInvalidArgument},
+		{"call function", TxPayloadCallType, []byte(`{"Function":"_private"}`), ErrInvalidCallFunction},
+		{"call args", TxPayloadCallType, []byte(`{"Function":"transfer","Args":"{"}`), ErrInvalidArgument},
+		{"call type with deploy data", TxPayloadCallType, deployBytes, ErrInvalidCallFunction},
+		{"deploy type with call data", TxPayloadDeployType, callBytes, ErrInvalidDeploySource},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tx := mockTransaction(1, 1, tt.payloadType, tt.payload)
+			assert.Equal(t, tt.err, tx.ValidatePayload())
+		})
+	}
+
+	tx := mockNormalTransaction(1, 1)
+	tx.data.Type = "unknown"
+	assert.Equal(t, ErrInvalidTxPayloadType, tx.ValidatePayload())
+
+	// payload type is signed
+	signed := mockTransaction(1, 1, TxPayloadCallType, callBytes)
+	assert.Nil(t, signTx(signed))
+	signed.data.Type = TxPayloadBinaryType
+	assert.Equal(t, ErrInvalidTransactionHash, signed.VerifyIntegrity(1))
+}
+
+func TestTransactions_AffordableFor(t *testing.T) {
+	from := mockAddress()
+	gasPrice, _ := util.NewUint128FromInt(1)
+	gasLimit, _ := util.NewUint128FromInt(20000)
+	// each tx costs value + 20000
+	txs := Transactions{
+		mockValueTransaction(from, 3, 50000),
+		mockValueTransaction(from, 1, 10000),
+		mockValueTransaction(mockAddress(), 1, 1),
+		mockValueTransaction(from, 2, 30000),
+		mockValueTransaction(from, 4, 0),
+	}
+	for _, tx := range txs {
+		tx.gasPrice, tx.gasLimit = gasPrice, gasLimit
+	}
+
+	tests := []struct {
+		name         string
+		balance      int64
+		affordable   []uint64
+		unaffordable []uint64
+	}{
+		{"enough", 1000000, []uint64{1, 2, 3, 4}, nil},
+		{"exact", 170000, []uint64{1, 2, 3, 4}, nil},
+		{"mid batch", 100000, []uint64{1, 2}, []uint64{3, 4}},
+		{"none", 29999, nil, []uint64{1, 2, 3, 4}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			balance, _ := util.NewUint128FromInt(tt.balance)
+			affordable, unaffordable := txs.AffordableFor(from, balance)
+			assert.Equal(t, tt.affordable, txNonces(affordable))
+			assert.Equal(t, tt.unaffordable, txNonces(unaffordable))
+			assert.Equal(t, uint64(tt.balance), balance.Uint64())
+		})
+	}
+}
+
+func TestTransactions_WithinSpendingCap(t *testing.T) {
+	from := mockAddress()
+	txs := Transactions{
+		mockValueTransaction(from, 3, 50000),
+		mockValueTransaction(from, 1, 10000),
+		mockValueTransaction(mockAddress(), 1, 1000000),
+		mockValueTransaction(from, 2, 30000),
+		mockValueTransaction(from, 4, 0),
+	}
+
+	tests := []struct {
+		name        string
+		spendingCap int64
+		allowed     []uint64
+		overCap     []uint64
+	}{
+		{"under", 1000000, []uint64{1, 2, 3, 4}, nil},
+		{"exact", 90000, []uint64{1, 2, 3, 4}, nil},
+		{"mid sequence", 89999, []uint64{1, 2}, []uint64{3, 4}},
+		{"none", 9999, nil, []uint64{1, 2, 3, 4}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			spendingCap, _ := util.NewUint128FromInt(tt.spendingCap)
+			allowed, overCap := txs.WithinSpendingCap(from, spendingCap)
+			assert.Equal(t, tt.allowed, txNonces(allowed))
+			assert.Equal(t, tt.overCap, txNonces(overCap))
+		})
+	}
+}
+
+func TestTransactions_EnforcePerSenderBlockCap(t *testing.T) {
+	a, b, c := mockAddress(), mockAddress(), mockAddress()
+	a3, a1, b1, a4, c1, a2, b2 := mockSenderTransaction(a, 3), mockSenderTransaction(a, 1), mockSenderTransaction(b, 1), mockSenderTransaction(a, 4), mockSenderTransaction(c, 1), mockSenderTransaction(a, 2), mockSenderTransaction(b, 2)
+	txs := Transactions{a3, a1, b1, a4, c1, a2, b2}
+
+	kept, rejected := txs.EnforcePerSenderBlockCap(2)
+	// a is over the cap, b is at it and c under it
+	assert.Equal(t, Transactions{a1, a2, b1, c1, b2}, kept)
+	assert.Equal(t, Transactions{a3, a4}, rejected)
+
+	kept, rejected = txs.EnforcePerSenderBlockCap(4)
+	assert.Equal(t, Transactions{a1, a2, b1, a3, c1, a4, b2}, kept)
+	assert.Nil(t, rejected)
+
+	kept, rejected = txs.EnforcePerSenderBlockCap(0)
+	assert.Nil(t, kept)
+	assert.Equal(t, 7, len(rejected))
+}
+
+func TestTransaction_GasRefund(t *testing.T) {
+	gasPrice, _ := util.NewUint128FromInt(1000000)
+	gasLimit, _ := util.NewUint128FromInt(30000)
+	tx, _ := NewTransaction(1, mockAddress(), mockAddress(), util.NewUint128(), 1, TxPayloadBinaryType, nil, gasPrice, gasLimit)
+
+	tests := []struct {
+		name    string
+		gasUsed int64
+		refund  string
+	}{
+		{"partial", 20000, "10000000000"},
+		{"full", 30000, "0"},
+		{"over", 40000, "0"},
+		{"none", 0, "30000000000"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gasUsed, _ := util.NewUint128FromInt(tt.gasUsed)
+			assert.Equal(t, tt.refund, tx.GasRefund(gasUsed).String())
+		})
+	}
+	assert.Equal(t, "0", tx.GasRefund(nil).String())
+}
+
+func TestTransaction_AlgorithmAddressMismatch(t *testing.T) {
+	tx := mockNormalTransaction(1, 1)
+	assert.Nil(t, signTx(tx))
+	assert.True(t, tx.from.SupportsAlgorithm(keystore.SECP256K1))
+	assert.Nil(t, tx.VerifyIntegrity(1))
+
+	contract, _ := NewContractAddressFromData(tx.from.Bytes(), byteutils.FromUint64(1))
+	assert.False(t, contract.SupportsAlgorithm(keystore.SECP256K1))
+	tx.from = contract
+	tx.hash, _ = tx.calHash()
+	assert.Equal(t, ErrAlgorithmAddressMismatch, tx.VerifyIntegrity(1))
+}
+
+func TestAlgorithmStats(t *testing.T) {
+	unknown := keystore.Algorithm(255)
+	before := AlgorithmStats()
+
+	txs := mockSignedTransactions(t, 6)
+	for _, tx := range txs[4:] {
+		tx.alg = unknown
+	}
+	var wg sync.WaitGroup
+	for _, tx := range txs {
+		wg.Add(1)
+		go func(tx *Transaction) {
+			defer wg.Done()
+			tx.VerifyIntegrity(1)
+		}(tx)
+	}
+	wg.Wait()
+
+	after := AlgorithmStats()
+	assert.Equal(t, uint64(4), after[keystore.SECP256K1]-before[keystore.SECP256K1])
+	_, found := after[unknown]
+	assert.False(t, found)
+
+	// returned stats is a copy
+	after[keystore.SECP256K1] = 0
+	assert.NotEqual(t, uint64(0), AlgorithmStats()[keystore.SECP256K1])
+}
+
+func TestTransactions_RemoveStale(t *testing.T) {
+	a, b, c := mockAddress(), mockAddress(), mockAddress()
+	txs := Transactions{
+		mockSenderTransaction(a, 3), mockSenderTransaction(b, 1), mockSenderTransaction(a, 4), mockSenderTransaction(c, 1), mockSenderTransaction(b, 5), mockSenderTransaction(a, 2),
+	}
+	accountNonces := map[string]uint64{
+		a.String(): 3,
+		b.String(): 0,
+	}
+	fresh := txs.RemoveStale(accountNonces)
+	assert.Equal(t, Transactions{txs[1], txs[2], txs[3], txs[4]}, fresh)
+	assert.Equal(t, txs, txs.RemoveStale(nil))
+
+	// account nonce at the top of the range must not wrap around
+	d := mockAddress()
+	maxed := Transactions{mockSenderTransaction(d, math.MaxUint64)}
+	assert.Nil(t, maxed.RemoveStale(map[string]uint64{d.String(): math.MaxUint64}))
+}
+
+func TestTransactions_WouldRegressNonce(t *testing.T) {
+	a, b, c := mockAddress(), mockAddress(), mockAddress()
+	txs := Transactions{
+		mockSenderTransaction(a, 3), mockSenderTransaction(b, 1), mockSenderTransaction(a, 4), mockSenderTransaction(c, 1), mockSenderTransaction(b, 5), mockSenderTransaction(a, 2),
+	}
+	for _, tx := range txs {
+		assert.Nil(t, signTx(tx))
+	}
+	accountNonces := map[string]uint64{
+		a.String(): 3,
+		b.String(): 0,
+	}
+	assert.Equal(t, []byteutils.Hash{txs[0].hash, txs[5].hash}, txs.WouldRegressNonce(accountNonces))
+	assert.Nil(t, txs[1:5].WouldRegressNonce(accountNonces))
+	assert.Nil(t, txs.WouldRegressNonce(nil))
+}
+
+func TestTransaction_TrieKey(t *testing.T) {
+	tx := mockNormalTransaction(1, 1)
+	assert.Nil(t, signTx(tx))
+	key := tx.TrieKey()
+	assert.Equal(t, []byte(tx.hash), key)
+
+	// key is a copy
+	key[0] ^= 0xff
+	assert.NotEqual(t, []byte(tx.hash), key)
+
+	stor, _ := storage.NewMemoryStorage()
+	txsTrie, _ := trie.NewTrie(nil, stor, false)
+	_, err := txsTrie.Put(tx.TrieKey(), []byte("tx"))
+	assert.Nil(t, err)
+	proof, err := txsTrie.Prove(tx.TrieKey())
+	assert.Nil(t, err)
+	assert.Nil(t, txsTrie.Verify(txsTrie.RootHash(), tx.TrieKey(), proof))
+}
+
+func TestTransactions_TotalIntrinsicGas(t *testing.T) {
+	var txs Transactions
+	for i := 1; i <= 20; i++ {
+		txs = append(txs, mockNormalTransaction(1, uint64(i)))
+	}
+	txs = append(txs, mockCallTransaction(1, 21, "totalSupply", ""))
+
+	gas, err := txs[0].IntrinsicGas()
+	assert.Nil(t, err)
+	// binary payload has no base gas
+	assert.Equal(t, MinGasCountPerTransaction, gas)
+	callGas, err := txs[20].IntrinsicGas()
+	assert.Nil(t, err)
+
+	total, err := txs.TotalIntrinsicGas()
+	assert.Nil(t, err)
+	want, _ := gas.Mul(util.NewUint128FromUint(20))
+	want, _ = want.Add(callGas)
+	assert.Equal(t, want, total)
+
+	empty, err := Transactions{}.TotalIntrinsicGas()
+	assert.Nil(t, err)
+	assert.Equal(t, "0", empty.String())
+
+	invalid := mockNormalTransaction(1, 22)
+	invalid.data.Type = "unknown"
+	_, err = append(txs, invalid).TotalIntrinsicGas()
+	assert.Equal(t, ErrInvalidTxPayloadType, err)
+}
+
+func TestTransaction_ValidForBaseFee(t *testing.T) {
+	gasPrice, _ := util.NewUint128FromInt(1000000)
+	tx, _ := NewTransaction(1, mockAddress(), mockAddress(), util.NewUint128(), 1, TxPayloadBinaryType, nil, gasPrice, TransactionMaxGas)
+
+	tests := []struct {
+		baseFee int64
+		err     error
+	}{
+		{0, nil},
+		{1, nil},
+		{999999, nil},
+		{1000000, nil},
+		{1000001, ErrFeeTooLowForBaseFee},
+		{2000000, ErrFeeTooLowForBaseFee},
+	}
+	for _, tt := range tests {
+		baseFee, _ := util.NewUint128FromInt(tt.baseFee)
+		assert.Equal(t, tt.err, tx.ValidForBaseFee(baseFee), "base fee %d", tt.baseFee)
+	}
+	assert.Equal(t, ErrNilArgument, tx.ValidForBaseFee(nil))
+}
+
+func TestTransactions_StripSignatures(t *testing.T) {
+	txs := mockSignedTransactions(t, 3)
+	bodies, sigs := txs.StripSignatures()
+	assert.Equal(t, len(txs), len(bodies))
+	for i, body := range bodies {
+		assert.Nil(t, body.sign)
+		assert.Equal(t, []byte(txs[i].sign), sigs[i])
+		assert.Equal(t, txs[i].hash, body.hash)
+		assert.NotNil(t, body.VerifyIntegrity(1))
+		// originals keep their signs
+		assert.Nil(t, txs[i].VerifyIntegrity(1))
+	}
+
+	restored, err := ReattachSignatures(bodies, sigs)
+	assert.Nil(t, err)
+	for i, tx := range restored {
+		assert.True(t, txs[i].Equals(tx))
+		assert.Nil(t, tx.VerifyIntegrity(1))
+	}
+
+	_, err = ReattachSignatures(bodies, sigs[:2])
+	assert.Equal(t, ErrInvalidArgument, err)
+}
+
+func TestTransactions_ShuffleDeterministic(t *testing.T) {
+	txs := mockSignedTransactions(t, 8)
+	reversed := make(Transactions, len(txs))
+	for i, tx := range txs {
+		reversed[len(txs)-1-i] = tx
+	}
+	seed := txs.OrderingSeed()
+	assert.Equal(t, seed, reversed.OrderingSeed())
+	assert.NotEqual(t, seed, txs[:7].OrderingSeed())
+
+	a := append(Transactions{}, txs...)
+	b := append(Transactions{}, reversed...)
+	a.ShuffleDeterministic(seed)
+	b.ShuffleDeterministic(seed)
+	assert.Equal(t, a, b)
+	assert.ElementsMatch(t, txs, a)
+
+	other := append(Transactions{}, txs...)
+	other.ShuffleDeterministic(byteutils.Hash("another seed"))
+	assert.NotEqual(t, a, other)
+}
+
+func TestNewCancelTransaction(t *testing.T) {
+	value, _ := util.NewUint128FromInt(100)
+	original, _ := NewTransaction(1, mockAddress(), mockAddress(), value, 5, TxPayloadBinaryType, []byte("data"), TransactionGasPrice, TransactionMaxGas)
+
+	cancel, err := NewCancelTransaction(original)
+	assert.Nil(t, err)
+	assert.True(t, cancel.from.Equals(original.from))
+	assert.True(t, cancel.to.Equals(original.from))
+	assert.Equal(t, original.nonce, cancel.nonce)
+	assert.Equal(t, "0", cancel.value.String())
+	assert.Equal(t, "1100000", cancel.gasPrice.String())
+	assert.True(t, cancel.IsCancelOf(original))
+	assert.True(t, cancel.IsSpeedUpOf(original))
+	assert.False(t, original.IsCancelOf(cancel))
+
+	// cancel is signed and verified as a normal tx
+	assert.Nil(t, signTx(cancel))
+	assert.Nil(t, cancel.VerifyIntegrity(1))
+
+	// small gasPrice is still bumped
+	one := util.NewUint128FromUint(1)
+	cheap, _ := NewTransaction(1, mockAddress(), mockAddress(), value, 5, TxPayloadBinaryType, nil, one, TransactionMaxGas)
+	cancel, err = NewCancelTransaction(cheap)
+	assert.Nil(t, err)
+	assert.Equal(t, "2", cancel.gasPrice.String())
+
+	// speed up with value is not a cancel
+	speedUp, _ := NewTransaction(1, original.from, original.from, value, 5, TxPayloadBinaryType, nil, TransactionMaxGasPrice, TransactionMaxGas)
+	assert.False(t, speedUp.IsCancelOf(original))
+
+	// max gasPrice can not be bumped
+	expensive, _ := NewTransaction(1, mockAddress(), mockAddress(), value, 5, TxPayloadBinaryType, nil, TransactionMaxGasPrice, TransactionMaxGas)
+	_, err = NewCancelTransaction(expensive)
+	assert.Equal(t, ErrInvalidGasPrice, err)
+}
+
+func TestTransaction_RespectsReserve(t *testing.T) {
+	value, _ := util.NewUint128FromInt(50000)
+	gasPrice := util.NewUint128FromUint(1)
+	gasLimit, _ := util.NewUint128FromInt(20000)
+	// required balance: 50000 + 20000
+	tx, _ := NewTransaction(1, mockAddress(), mockAddress(), value, 1, TxPayloadBinaryType, nil, gasPrice, gasLimit)
+	required, err := tx.RequiredBalance()
+	assert.Nil(t, err)
+	assert.Equal(t, "70000", required.String())
+
+	tests := []struct {
+		name     string
+		balance  uint64
+		reserve  uint64
+		respects bool
+	}{
+		{"no reserve", 70000, 0, true},
+		{"exact reserve", 100000, 30000, true},
+		{"breach reserve", 100000, 30001, false},
+		{"insufficient balance", 60000, 0, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.respects, tx.RespectsReserve(util.NewUint128FromUint(tt.balance), util.NewUint128FromUint(tt.reserve)))
+		})
+	}
+	assert.False(t, tx.RespectsReserve(nil, util.NewUint128()))
+}
+
+func TestTransaction_IdempotencyKey(t *testing.T) {
+	tx := mockNormalTransaction(1, 3)
+	assert.Nil(t, signTx(tx))
+
+	retry := tx.Clone()
+	retry.timestamp = tx.timestamp + 10
+	retry.gasPrice = TransactionMaxGasPrice
+	assert.Nil(t, signTx(retry))
+	assert.NotEqual(t, tx.hash, retry.hash)
+	assert.Equal(t, tx.IdempotencyKey(), retry.IdempotencyKey())
+	assert.True(t, strings.HasPrefix(tx.IdempotencyKey(), tx.from.address.String()+":3:"))
+
+	other := tx.Clone()
+	other.value = util.NewUint128FromUint(1)
+	assert.NotEqual(t, tx.IdempotencyKey(), other.IdempotencyKey())
+
+	next := tx.Clone()
+	next.nonce = 4
+	assert.NotEqual(t, tx.IdempotencyKey(), next.IdempotencyKey())
+}
+
+type mockContractValidator struct {
+	valid bool
+	err   error
+	calls int
+}
+
+func (v *mockContractValidator) IsValid(addr *Address, hash byteutils.Hash, sign []byte) (bool, error) {
+	v.calls++
+	return v.valid, v.err
+}
+
+func TestTransaction_VerifyContractSignature(t *testing.T) {
+	tx := mockNormalTransaction(1, 1)
+	contract, _ := NewContractAddressFromData(tx.from.Bytes(), byteutils.FromUint64(1))
+	tx.from = contract
+	tx.hash, _ = tx.calHash()
+	tx.sign = []byte("contract wallet sign")
+
+	valid := &mockContractValidator{valid: true}
+	assert.Nil(t, tx.VerifyContractSignature(1, valid))
+	assert.Equal(t, 1, valid.calls)
+	assert.Equal(t, ErrInvalidChainID, tx.VerifyContractSignature(2, valid))
+
+	invalid := &mockContractValidator{valid: false}
+	assert.Equal(t, ErrInvalidSignature, tx.VerifyContractSignature(1, invalid))
+	failed := &mockContractValidator{err: ErrContractCheckFailed}
+	assert.Equal(t, ErrContractCheckFailed, tx.VerifyContractSignature(1, failed))
+	assert.Equal(t, ErrNilArgument, tx.VerifyContractSignature(1, nil))
+
+	tampered := tx.Clone()
+	tampered.nonce = 2
+	assert.Equal(t, ErrHashMismatch, tampered.VerifyContractSignature(1, valid))
+
+	account := mockNormalTransaction(1, 1)
+	assert.Nil(t, signTx(account))
+	assert.Equal(t, ErrNotContractSender, account.VerifyContractSignature(1, valid))
+}
+
+func TestTransactions_SortByEffectiveTip(t *testing.T) {
+	txs := Transactions{mockPricedTransaction(mockAddress(), 1, 1000), mockPricedTransaction(mockAddress(), 2, 3000), mockPricedTransaction(mockAddress(), 3, 2000), mockPricedTransaction(mockAddress(), 4, 1500)}
+	assert.Equal(t, "500", txs[3].EffectiveTip(util.NewUint128FromUint(1000)).String())
+	assert.Equal(t, "0", txs[0].EffectiveTip(util.NewUint128FromUint(1500)).String())
+
+	low := append(Transactions{}, txs...)
+	low.SortByEffectiveTip(util.NewUint128FromUint(500))
+	assert.Equal(t, []uint64{2, 3, 4, 1}, txNonces(low))
+
+	// txs below base fee earn nothing and keep their order
+	high := append(Transactions{}, txs...)
+	high.SortByEffectiveTip(util.NewUint128FromUint(2500))
+	assert.Equal(t, []uint64{2, 1, 3, 4}, txNonces(high))
+}
+
+func TestTransaction_ClockSkew(t *testing.T) {
+	tx := mockNormalTransaction(1, 1)
+	assert.True(t, tx.ClockSkew() <= 0 && tx.ClockSkew() > -2*time.Second)
+	assert.Nil(t, tx.CheckClockSkew(time.Minute))
+
+	tx.timestamp = time.Now().Add(-time.Hour).Unix()
+	skew := tx.ClockSkew()
+	assert.True(t, skew < -59*time.Minute && skew > -61*time.Minute)
+	assert.Equal(t, ErrTransactionClockSkew, tx.CheckClockSkew(time.Minute))
+	assert.Nil(t, tx.CheckClockSkew(2*time.Hour))
+
+	tx.timestamp = time.Now().Add(10 * time.Minute).Unix()
+	skew = tx.ClockSkew()
+	assert.True(t, skew > 9*time.Minute && skew <= 10*time.Minute)
+	assert.Equal(t, ErrTransactionClockSkew, tx.CheckClockSkew(time.Minute))
+	assert.Nil(t, tx.CheckClockSkew(11*time.Minute))
+}
+
+func TestTransaction_WithIncrementedNonce(t *testing.T) {
+	tx := mockNormalTransaction(1, 5)
+	assert.Nil(t, signTx(tx))
+	hash, sign := tx.hash, tx.sign
+
+	next, err := tx.WithIncrementedNonce()
+	assert.Nil(t, err)
+	assert.Equal(t, uint64(6), next.nonce)
+	assert.Nil(t, next.hash)
+	assert.Nil(t, next.sign)
+	assert.NotNil(t, next.VerifyIntegrity(1))
+	assert.True(t, next.from.Equals(tx.from))
+
+	// original is unchanged
+	assert.Equal(t, uint64(5), tx.nonce)
+	assert.Equal(t, hash, tx.hash)
+	assert.Equal(t, sign, tx.sign)
+	assert.Nil(t, tx.VerifyIntegrity(1))
+
+	assert.Nil(t, signTx(next))
+	assert.Nil(t, next.VerifyIntegrity(1))
+
+	tx.nonce = math.MaxUint64
+	_, err = tx.WithIncrementedNonce()
+	assert.Equal(t, ErrNonceOverflow, err)
+}
+
+func TestTransaction_IsBurn(t *testing.T) {
+	from := mockAddress()
+	zero := mockZeroAddress(AccountAddress)
+	deployPayload, _ := NewDeployPayload("function F(){}", "js", "")
+	deploy, _ := deployPayload.ToBytes()
+
+	burn := mockPayloadTransaction(from, zero, 100, TxPayloadBinaryType, nil)
+	zeroValue := mockPayloadTransaction(from, zero, 0, TxPayloadBinaryType, nil)
+	deployToZero := mockPayloadTransaction(from, zero, 50, TxPayloadDeployType, deploy)
+	deployToSelf := mockPayloadTransaction(from, from, 50, TxPayloadDeployType, deploy)
+	transfer := mockPayloadTransaction(from, mockAddress(), 100, TxPayloadBinaryType, nil)
+
+	assert.True(t, burn.IsBurn())
+	assert.False(t, zeroValue.IsBurn())
+	assert.False(t, deployToZero.IsBurn())
+	assert.False(t, deployToSelf.IsBurn())
+	assert.False(t, transfer.IsBurn())
+
+	total, err := Transactions{burn, zeroValue, deployToZero, deployToSelf, transfer, mockPayloadTransaction(from, zero, 23, TxPayloadBinaryType, nil)}.TotalBurned()
+	assert.Nil(t, err)
+	assert.Equal(t, "123", total.String())
+
+	empty, err := Transactions{}.TotalBurned()
+	assert.Nil(t, err)
+	assert.Equal(t, "0", empty.String())
+}
+
+func TestTransaction_StorageCID(t *testing.T) {
+	tx := mockNormalTransaction(1, 1)
+	assert.Nil(t, signTx(tx))
+	cid := tx.StorageCID()
+	assert.True(t, strings.HasPrefix(cid, "Qm"))
+	assert.Equal(t, 46, len(cid))
+
+	annotated := tx.Clone()
+	annotated.SetAnnotation("label", "exchange deposit")
+	annotated.SetReplacesHash(mockNormalTransaction(1, 1).hash)
+	assert.Equal(t, cid, annotated.StorageCID())
+
+	other := tx.Clone()
+	other.nonce++
+	assert.NotEqual(t, cid, other.StorageCID())
+	unsigned := tx.Clone()
+	unsigned.sign = nil
+	assert.NotEqual(t, cid, unsigned.StorageCID())
+}
+
+func TestTransaction_ValueEncoding(t *testing.T) {
+	// value is a Uint128 serialized as 16 fixed-size big-endian bytes, every value has a single encoding.
+	large, err := util.NewUint128FromString("1000000000000000000000000") // 10^6 NAS, beyond uint64
+	assert.Nil(t, err)
+	for _, value := range []*util.Uint128{util.NewUint128(), util.NewUint128FromUint(1), large} {
+		tx, err := NewTransaction(1, mockAddress(), mockAddress(), value, 1, TxPayloadBinaryType, nil, TransactionGasPrice, TransactionMaxGas)
+		assert.Nil(t, err)
+		assert.Nil(t, signTx(tx))
+
+		pbTx, err := tx.ToProto()
+		assert.Nil(t, err)
+		assert.Equal(t, util.Uint128Bytes, len(pbTx.(*corepb.Transaction).Value))
+
+		ntx := new(Transaction)
+		assert.Nil(t, ntx.FromProto(pbTx))
+		assert.Equal(t, value.String(), ntx.value.String())
+		hash, err := ntx.calHash()
+		assert.Nil(t, err)
+		assert.Equal(t, tx.hash, hash)
+	}
+
+	_, err = NewTransaction(1, mockAddress(), mockAddress(), nil, 1, TxPayloadBinaryType, nil, TransactionGasPrice, TransactionMaxGas)
+	assert.Equal(t, ErrInvalidArgument, err)
+}
+
+func TestTransaction_IsSimpleTransfer(t *testing.T) {
+	from := mockAddress()
+	contract, _ := AddressParse("n1sLnoc7j57YfzAVP8tJ3yK5a2i56QrTDdK")
+	multiSend, _ := NewCallPayload("multiSend", `[["n1FF1nz6tarkDVwWQkMnnwFPuPKUaQTdptE", "n1GmkKH6nBMw4rrjt16RrJ9WcgvKUtAZP1s"], ["1", "2"]]`)
+	multiSendBytes, _ := multiSend.ToBytes()
+
+	tests := []struct {
+		name string
+		tx   *Transaction
+		want bool
+	}{
+		{"transfer", mockPayloadTransaction(from, mockAddress(), 1, TxPayloadBinaryType, nil), true},
+		{"transfer with data", mockPayloadTransaction(from, mockAddress(), 1, TxPayloadBinaryType, []byte("memo")), false},
+		{"transfer to contract", mockPayloadTransaction(from, contract, 1, TxPayloadBinaryType, nil), false},
+		{"call", mockCallTransaction(1, 1, "totalSupply", ""), false},
+		{"multi send", mockPayloadTransaction(from, contract, 1, TxPayloadCallType, multiSendBytes), false},
+		{"deploy", mockDeployTransaction(1, 1), false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, tt.tx.IsSimpleTransfer())
+		})
+	}
+}
+
+func TestTransaction_Fee(t *testing.T) {
+	tx := mockNormalTransaction(1, 1)
+	fee, err := tx.Fee()
+	assert.Nil(t, err)
+	want, _ := TransactionGasPrice.Mul(TransactionMaxGas)
+	assert.Equal(t, want, fee)
+
+	expensive, _ := NewTransaction(1, mockAddress(), mockAddress(), util.NewUint128(), 1, TxPayloadBinaryType, nil, TransactionMaxGasPrice, TransactionMaxGas)
+	fee, err = expensive.Fee()
+	assert.Nil(t, err)
+	assert.Equal(t, "50000000000000000000000", fee.String())
+}
+
+func TestTransaction_SignWithHDPath(t *testing.T) {
+	seed, _ := hex.DecodeString("000102030405060708090a0b0c0d0e0f")
+	tx := mockNormalTransaction(1, 1)
+	assert.Nil(t, tx.SignWithHDPath(seed, "m/44'/2718'/0'/0/0", keystore.SECP256K1))
+	assert.Nil(t, tx.VerifyIntegrity(1))
+	assert.Equal(t, "n1b5jPG34CSJaPvCNdS2rJJygMtD5rteeFD", tx.from.String())
+
+	// same seed and path always derive the same signer.
+	seckey, err := secp256k1.DeriveHDPrivateKey(seed, "m/44'/2718'/0'/0/0")
+	assert.Nil(t, err)
+	pub, err := secp256k1.GetPublicKey(seckey)
+	assert.Nil(t, err)
+	want, err := NewAddressFromPublicKey(pub)
+	assert.Nil(t, err)
+	assert.Equal(t, want, tx.from)
+
+	other := mockNormalTransaction(1, 1)
+	assert.Nil(t, other.SignWithHDPath(seed, "m/44'/2718'/0'/0/1", keystore.SECP256K1))
+	assert.False(t, other.from.Equals(tx.from))
+
+	assert.Equal(t, secp256k1.ErrInvalidHDPath, other.SignWithHDPath(seed, "m/44'/x", keystore.SECP256K1))
+	assert.Equal(t, secp256k1.ErrInvalidHDSeed, other.SignWithHDPath(seed[:4], "m/0", keystore.SECP256K1))
+	assert.Equal(t, crypto.ErrAlgorithmInvalid, other.SignWithHDPath(seed, "m/0", keystore.Algorithm(100)))
+}
+
+func TestTransaction_VerifyIntegrityForeignChain(t *testing.T) {
+	// a tx legitimately signed for testnet
+	tx := mockNormalTransaction(1001, 1)
+	assert.Nil(t, signTx(tx))
+	assert.Nil(t, tx.VerifyIntegrity(1001))
+	assert.Equal(t, ErrInvalidChainID, tx.VerifyIntegrity(1))
+
+	// tampered chainID breaks the hash
+	tx.chainID = 1
+	assert.Equal(t, ErrInvalidTransactionHash, tx.VerifyIntegrity(1))
+}
+
+func TestTransaction_VerifyIntrinsicGas(t *testing.T) {
+	from, to := mockAddress(), mockAddress()
+	intrinsic, err := mockNormalTransaction(1, 1).IntrinsicGas()
+	assert.Nil(t, err)
+	below, _ := intrinsic.Sub(util.NewUint128FromUint(1))
+	above, _ := intrinsic.Add(util.NewUint128FromUint(1))
+
+	tests := []struct {
+		name     string
+		gasLimit *util.Uint128
+		err      error
+	}{
+		{"below", below, ErrGasLimitBelowIntrinsic},
+		{"at", intrinsic, nil},
+		{"above", above, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tx, _ := NewTransaction(1, from, to, util.NewUint128(), 1, TxPayloadBinaryType, nil, TransactionGasPrice, tt.gasLimit)
+			assert.Equal(t, tt.err, tx.VerifyIntrinsicGas())
+		})
+	}
+
+	invalid := mockNormalTransaction(1, 1)
+	invalid.data.Type = "unknown"
+	assert.Equal(t, ErrInvalidTxPayloadType, invalid.VerifyIntrinsicGas())
+}
+
+// BenchmarkTransactions_VerifyIntegrity verify a batch of 1000 txs, each tx is hashed once to check the stored hash.
+func BenchmarkTransactions_VerifyIntegrity(b *testing.B) {
+	priv := secp256k1.GeneratePrivateKey()
+	seckey, _ := priv.Encoded()
+	pub, _ := priv.PublicKey().Encoded()
+	from, _ := NewAddressFromPublicKey(pub)
+	to := mockAddress()
+	txs := make(Transactions, 1000)
+	for i := range txs {
+		txs[i], _ = NewTransaction(1, from, to, util.NewUint128FromUint(1), uint64(i+1), TxPayloadBinaryType, nil, TransactionGasPrice, TransactionMaxGas)
+		if err := txs[i].SignWithRawKey(seckey, keystore.SECP256K1); err != nil {
+			b.Fatal(err)
+		}
+	}
+
+	b.ResetTimer()
+	for n := 0; n < b.N; n++ {
+		for _, tx := range txs {
+			if err := tx.VerifyIntegrity(1); err != nil {
+				b.Fatal(err)
+			}
+		}
+	}
+}
+
+func TestTransaction_CachedHash(t *testing.T) {
+	tx := mockNormalTransaction(1, 1)
+	hash, err := tx.fieldsHash()
+	assert.Nil(t, err)
+	assert.Equal(t, hash, tx.cachedHash)
+	// the hash of an unsigned tx is not exposed.
+	assert.Nil(t, tx.Hash())
+
+	// setters drop the cache.
+	assert.Nil(t, tx.SetReplaySalt(mockReplaySalt()))
+	assert.Nil(t, tx.cachedHash)
+
+	// signing caches the signed hash.
+	assert.Nil(t, signTx(tx))
+	assert.Equal(t, tx.hash, tx.cachedHash)
+	assert.Nil(t, tx.Clone().cachedHash)
+
+	// verification recomputes the hash, a stale cache is not trusted.
+	tx.cachedHash = hash
+	assert.Nil(t, tx.VerifyIntegrity(1))
+	assert.Equal(t, tx.hash, tx.cachedHash)
+	tx.nonce++
+	assert.Equal(t, ErrInvalidTransactionHash, tx.VerifyIntegrity(1))
+	assert.Equal(t, ErrHashMismatch, tx.ValidateStoredHash())
+	tx.nonce--
+
+	pbTx, err := tx.ToProto()
+	assert.Nil(t, err)
+	decoded := new(Transaction)
+	decoded.cachedHash = hash
+	assert.Nil(t, decoded.FromProto(pbTx))
+	assert.Nil(t, decoded.cachedHash)
+
+	// the cache is not reused under another hash domain.
+	defer SetTxHashDomain(nil)
+	SetTxHashDomain([]byte("subchain-a"))
+	unsigned := mockNormalTransaction(1, 2)
+	hashA, err := unsigned.fieldsHash()
+	assert.Nil(t, err)
+	SetTxHashDomain([]byte("subchain-b"))
+	hashB, err := unsigned.fieldsHash()
+	assert.Nil(t, err)
+	assert.NotEqual(t, hashA, hashB)
+}
+
+func TestNewTransaction_Address(t *testing.T) {
+	valid := mockAddress()
+	badChecksum := &Address{address: append([]byte{}, valid.address...)}
+	badChecksum.address[AddressLength-1] ^= 0xff
+	badType := &Address{address: append([]byte{}, valid.address...)}
+	badType.address[AddressTypeIndex] = 0x01
+	short := &Address{address: valid.address[:AddressDataEnd]}
+
+	tests := []struct {
+		name     string
+		from, to *Address
+		err      error
+	}{
+		{"valid", valid, mockAddress(), nil},
+		{"self", valid, valid, nil},
+		{"nil to", valid, nil, ErrInvalidArgument},
+		{"empty from", &Address{}, valid, ErrInvalidAddressFormat},
+		{"empty to", valid, &Address{}, ErrInvalidAddressFormat},
+		{"short to", valid, short, ErrInvalidAddressFormat},
+		{"bad checksum", valid, badChecksum, ErrInvalidAddressChecksum},
+		{"bad type", badType, valid, ErrInvalidAddressType},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewTransaction(1, tt.from, tt.to, util.NewUint128(), 1, TxPayloadBinaryType, nil, TransactionGasPrice, TransactionMaxGas)
+			assert.Equal(t, tt.err, err)
+		})
+	}
+}
+
+func TestTransactions_CommitmentEquals(t *testing.T) {
+	signed := mockSignedTransactions(t, 4)
+	a, b, c, d := signed[0], signed[1], signed[2], signed[3]
+	txs := Transactions{a, b, c}
+
+	assert.True(t, txs.CommitmentEquals(Transactions{a, b, c}))
+	assert.True(t, txs.SetEquals(Transactions{a, b, c}))
+
+	// reordering changes the commitment but not the set
+	reordered := Transactions{c, a, b}
+	assert.False(t, txs.CommitmentEquals(reordered))
+	assert.True(t, txs.SetEquals(reordered))
+	assert.NotEqual(t, txs.MerkleRoot(), reordered.MerkleRoot())
+
+	// duplicating the odd leaf is a different batch
+	assert.False(t, txs.CommitmentEquals(Transactions{a, b, c, c}))
+	assert.False(t, txs.SetEquals(Transactions{a, b, c, c}))
+	assert.False(t, Transactions{a, b, b}.SetEquals(Transactions{a, a, b}))
+	assert.False(t, txs.SetEquals(Transactions{a, b, d}))
+
+	assert.True(t, Transactions{}.CommitmentEquals(nil))
+	assert.True(t, Transactions{}.SetEquals(nil))
+	assert.False(t, Transactions{a}.CommitmentEquals(nil))
+}
+
+func TestTransaction_VerifyTimestamp(t *testing.T) {
+	now := time.Unix(1530000000, 0)
+	window := time.Hour
+	tx := mockNormalTransaction(1, 1)
+
+	tests := []struct {
+		name      string
+		timestamp int64
+		err       error
+	}{
+		{"now", now.Unix(), nil},
+		{"window edge", now.Unix() - 3600, nil},
+		{"past window", now.Unix() - 3601, ErrExpiredTransaction},
+		{"skew edge", now.Unix() + 15, nil},
+		{"past skew", now.Unix() + 16, ErrFutureTransaction},
+		{"far future", math.MaxInt64, ErrFutureTransaction},
+		{"negative", -1, ErrExpiredTransaction},
+		{"far past", math.MinInt64, ErrExpiredTransaction},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tx.timestamp = tt.timestamp
+			assert.Equal(t, tt.err, tx.VerifyTimestamp(now, window))
+		})
+	}
+}
+
+func TestTransaction_PremiumOverMedian(t *testing.T) {
+	median := util.NewUint128FromUint(1000000)
+	tests := []struct {
+		name     string
+		gasPrice uint64
+		premium  int64
+	}{
+		{"above", 1500000, 500000},
+		{"at", 1000000, 0},
+		{"below", 400000, -600000},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tx, _ := NewTransaction(1, mockAddress(), mockAddress(), util.NewUint128(), 1, TxPayloadBinaryType, nil, util.NewUint128FromUint(tt.gasPrice), TransactionMaxGas)
+			assert.Equal(t, tt.premium, tx.PremiumOverMedian(median).Int64())
+		})
+	}
+
+	// max gas price
+	tx, _ := NewTransaction(1, mockAddress(), mockAddress(), util.NewUint128(), 1, TxPayloadBinaryType, nil, TransactionMaxGasPrice, TransactionMaxGas)
+	assert.Equal(t, "999999000000", tx.PremiumOverMedian(median).String())
+}
+
+func TestTransaction_MarginalPrice(t *testing.T) {
+	tx, _ := NewTransaction(1, mockAddress(), mockAddress(), util.NewUint128(), 1, TxPayloadBinaryType, nil, util.NewUint128FromUint(1000000), util.NewUint128FromUint(20000))
+	tests := []struct {
+		name     string
+		used     uint64
+		limit    uint64
+		marginal int64
+	}{
+		{"empty block", 0, 1000000000, 999980},
+		{"low utilization", 100000000, 1000000000, 999977},
+		{"high utilization", 999900000, 1000000000, 800000},
+		{"last fit", 999980000, 1000000000, 0},
+		{"not fit", 999990000, 1000000000, 0},
+		{"full", 1000000000, 1000000000, 0},
+		{"over full", 1000000001, 1000000000, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.marginal, tx.MarginalPrice(tt.used, tt.limit).Int64())
+		})
+	}
+	assert.True(t, tx.MarginalPrice(0, 1000000000).Cmp(tx.MarginalPrice(999900000, 1000000000)) > 0)
+}
+
+func TestTransaction_EffectiveCost(t *testing.T) {
+	value := util.NewUint128FromUint(5000)
+	tx, _ := NewTransaction(1, mockAddress(), mockAddress(), value, 1, TxPayloadBinaryType, nil, util.NewUint128FromUint(1000000), util.NewUint128FromUint(20000))
+	// value + gasPrice * gasLimit
+	maxCost := big.NewInt(5000 + 1000000*20000)
+	tests := []struct {
+		name    string
+		baseFee *util.Uint128
+		cost    *big.Int
+	}{
+		{"no base fee", nil, maxCost},
+		{"zero base fee", util.NewUint128(), maxCost},
+		{"low base fee", util.NewUint128FromUint(1), maxCost},
+		{"base fee at gas price", util.NewUint128FromUint(1000000), maxCost},
+		{"base fee over gas price", util.NewUint128FromUint(1000001), new(big.Int)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cost := tx.EffectiveCost(tt.baseFee)
+			assert.True(t, cost.Cmp(maxCost) <= 0)
+			assert.Equal(t, 0, tt.cost.Cmp(cost))
+		})
+	}
+
+	// the cost of max gas price and limit doesn't overflow.
+	maxUint128 := new(big.Int).Sub(new(big.Int).Lsh(big.NewInt(1), 128), big.NewInt(1))
+	huge := tx.Clone()
+	huge.gasPrice, _ = util.NewUint128FromBigInt(maxUint128)
+	huge.gasLimit, _ = util.NewUint128FromBigInt(maxUint128)
+	_, err := huge.RequiredBalance()
+	assert.NotNil(t, err)
+	want := new(big.Int).Mul(maxUint128, maxUint128)
+	assert.Equal(t, 0, want.Add(want, big.NewInt(5000)).Cmp(huge.EffectiveCost(util.NewUint128FromUint(1))))
+}
+
+func TestTransaction_SuspiciousGasRatio(t *testing.T) {
+	gasPrice := util.NewUint128FromUint(1000000)
+	gasLimit := util.NewUint128FromUint(20000)
+	// fee is 2 * 10^10
+	tests := []struct {
+		name       string
+		value      uint64
+		payload    string
+		suspicious bool
+	}{
+		{"normal", 1000000000000, TxPayloadBinaryType, false},
+		{"at threshold", 200000000000, TxPayloadBinaryType, false},
+		{"suspicious", 100000000000, TxPayloadBinaryType, true},
+		{"dust", 1, TxPayloadBinaryType, true},
+		{"zero value transfer", 0, TxPayloadBinaryType, true},
+		{"zero value call", 0, TxPayloadCallType, false},
+		{"dust call", 1, TxPayloadCallType, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var payload []byte
+			if tt.payload == TxPayloadCallType {
+				call, _ := NewCallPayload("totalSupply", "")
+				payload, _ = call.ToBytes()
+			}
+			tx, err := NewTransaction(1, mockAddress(), mockAddress(), util.NewUint128FromUint(tt.value), 1, tt.payload, payload, gasPrice, gasLimit)
+			assert.Nil(t, err)
+			assert.Equal(t, tt.suspicious, tx.SuspiciousGasRatio(0.1))
+		})
+	}
+}
+
+func TestTransaction_SequencePosition(t *testing.T) {
+	tests := []struct {
+		name         string
+		nonce        uint64
+		accountNonce uint64
+		position     int
+	}{
+		{"next", 5, 4, 0},
+		{"first", 1, 0, 0},
+		{"future", 7, 4, 2},
+		{"executed", 4, 4, -1},
+		{"past", 1, 4, -4},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tx := mockNormalTransaction(1, tt.nonce)
+			assert.Equal(t, tt.position, tx.SequencePosition(tt.accountNonce))
+		})
+	}
+}
+
+func TestTransaction_WithinNonceHorizon(t *testing.T) {
+	tests := []struct {
+		name   string
+		nonce  uint64
+		within bool
+	}{
+		{"stale", 3, true},
+		{"next", 6, true},
+		{"horizon", 15, true},
+		{"beyond horizon", 16, false},
+		{"max", math.MaxUint64, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tx := mockNormalTransaction(1, tt.nonce)
+			assert.Equal(t, tt.within, tx.WithinNonceHorizon(5, 10))
+		})
+	}
+	assert.True(t, mockNormalTransaction(1, math.MaxUint64).WithinNonceHorizon(math.MaxUint64-1, math.MaxUint64))
+
+	a, b, c := mockAddress(), mockAddress(), mockAddress()
+	txs := Transactions{
+		mockSenderTransaction(a, 3), mockSenderTransaction(b, 12), mockSenderTransaction(a, 13), mockSenderTransaction(c, 100), mockSenderTransaction(b, 11), mockSenderTransaction(a, 14),
+	}
+	accountNonces := map[string]uint64{
+		a.String(): 3,
+		b.String(): 1,
+	}
+	assert.Equal(t, Transactions{txs[0], txs[2], txs[3], txs[4]}, txs.FilterNonceHorizon(accountNonces, 10))
+	assert.Equal(t, txs, txs.FilterNonceHorizon(nil, 0))
+}
+
+func TestTransactions_RouteByChainID(t *testing.T) {
+	txs := Transactions{
+		mockNormalTransaction(1, 1), mockNormalTransaction(1001, 1), mockNormalTransaction(100, 1),
+		mockNormalTransaction(1, 2), mockNormalTransaction(1001, 2), mockNormalTransaction(2, 1),
+	}
+	allowed := []uint32{1, 1001}
+	assert.True(t, txs[0].ChainIDAllowed(allowed))
+	assert.False(t, txs[2].ChainIDAllowed(allowed))
+	assert.False(t, txs[0].ChainIDAllowed(nil))
+
+	routes, dropped := txs.RouteByChainID(allowed)
+	assert.Equal(t, 2, len(routes))
+	assert.Equal(t, Transactions{txs[0], txs[3]}, routes[1])
+	assert.Equal(t, Transactions{txs[1], txs[4]}, routes[1001])
+	assert.Equal(t, Transactions{txs[2], txs[5]}, dropped)
+
+	routes, dropped = txs.RouteByChainID(nil)
+	assert.Empty(t, routes)
+	assert.Equal(t, txs, dropped)
+}
+
+func TestTransaction_HashDomain(t *testing.T) {
+	defer SetTxHashDomain(nil)
+
+	tx := mockNormalTransaction(1, 1)
+	mainHash, err := tx.calHash()
+	assert.Nil(t, err)
+
+	SetTxHashDomain([]byte("subchain-a"))
+	assert.Equal(t, []byte("subchain-a"), TxHashDomain())
+	assert.Nil(t, signTx(tx))
+	assert.NotEqual(t, mainHash, tx.hash)
+	assert.Nil(t, tx.VerifyIntegrity(1))
+
+	SetTxHashDomain([]byte("subchain-b"))
+	assert.Equal(t, ErrInvalidTransactionHash, tx.VerifyIntegrity(1))
+	SetTxHashDomain(nil)
+	assert.Equal(t, ErrInvalidTransactionHash, tx.VerifyIntegrity(1))
+
+	// main chain hash is unchanged without a domain
+	hash, err := tx.calHash()
+	assert.Nil(t, err)
+	assert.Equal(t, mainHash, hash)
+}
+
+func TestTransactions_ByPreferredBuilder(t *testing.T) {
+	builderA, builderB := mockAddress(), mockAddress()
+	txs := mockSignedTransactions(t, 5)
+	txs[0].SetPreferredBuilder(builderA)
+	txs[2].SetPreferredBuilder(builderB)
+	txs[3].SetPreferredBuilder(builderA)
+
+	groups := txs.ByPreferredBuilder()
+	assert.Equal(t, 3, len(groups))
+	assert.Equal(t, Transactions{txs[0], txs[3]}, groups[builderA.String()])
+	assert.Equal(t, Transactions{txs[2]}, groups[builderB.String()])
+	assert.Equal(t, Transactions{txs[1], txs[4]}, groups[""])
+
+	// builder is routing metadata, it's neither hashed nor serialized
+	hash, err := txs[0].calHash()
+	assert.Nil(t, err)
+	assert.Equal(t, txs[0].hash, hash)
+	assert.Nil(t, txs[0].VerifyIntegrity(1))
+	plain := txs[0].Clone()
+	plain.SetPreferredBuilder(nil)
+	pbTx, err := txs[0].ToProto()
+	assert.Nil(t, err)
+	pbPlain, err := plain.ToProto()
+	assert.Nil(t, err)
+	assert.Equal(t, pbPlain, pbTx)
+	decoded := new(Transaction)
+	assert.Nil(t, decoded.FromProto(pbTx))
+	assert.Nil(t, decoded.PreferredBuilder())
+}
+
+func TestTransactions_SignEach(t *testing.T) {
+	txs := Transactions{mockNormalTransaction(1, 1), mockNormalTransaction(1, 2), mockNormalTransaction(1, 3)}
+	secp256k1Only := func(*Address) keystore.Algorithm { return keystore.SECP256K1 }
+	assert.Nil(t, txs.SignEach(keystore.DefaultKS, secp256k1Only))
+	for _, tx := range txs {
+		assert.Equal(t, keystore.SECP256K1, tx.alg)
+		assert.Nil(t, tx.VerifyIntegrity(1))
+	}
+
+	// only secp256k1 is built into keystore, an unknown algorithm fails at its index
+	unsupported := keystore.Algorithm(2)
+	txs = Transactions{mockNormalTransaction(1, 1), mockNormalTransaction(1, 2), mockNormalTransaction(1, 3)}
+	bySender := func(addr *Address) keystore.Algorithm {
+		if addr.Equals(txs[1].from) {
+			return unsupported
+		}
+		return keystore.SECP256K1
+	}
+	err := txs.SignEach(keystore.DefaultKS, bySender)
+	indexErr, ok := err.(*TxIndexError)
+	assert.True(t, ok)
+	assert.Equal(t, 1, indexErr.Index)
+	assert.Equal(t, crypto.ErrAlgorithmInvalid, indexErr.Err)
+	assert.Equal(t, "transaction 1: "+crypto.ErrAlgorithmInvalid.Error(), err.Error())
+	assert.Nil(t, txs[0].VerifyIntegrity(1))
+	assert.Nil(t, txs[2].sign)
+
+	// senders not in the keystore
+	unknown := mockNormalTransaction(1, 1)
+	unknown.from, _ = AddressParse("n1FkntVUMPAsESuCAAPK711omQk19JotBjM")
+	err = Transactions{unknown}.SignEach(keystore.DefaultKS, secp256k1Only)
+	indexErr, ok = err.(*TxIndexError)
+	assert.True(t, ok)
+	assert.Equal(t, 0, indexErr.Index)
+	assert.NotNil(t, indexErr.Err)
+
+	assert.Equal(t, ErrNilArgument, txs.SignEach(nil, secp256k1Only))
+}
@@ -0,0 +1,52 @@
+// Copyright (C) 2017 go-nebulas authors
+//
+// This file is part of the go-nebulas library.
+//
+// the go-nebulas library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// the go-nebulas library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with the go-nebulas library.  If not, see <http://www.gnu.org/licenses/>.
+//
+
+package core
+
+import (
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTransaction_URLSafe(t *testing.T) {
+	tx := mockCallTransaction(1, 1, "transfer", `["n1FF1nz6tarkDVwWQkMnnwFPuPKUaQTdptE", "100"]`)
+	assert.Nil(t, signTx(tx))
+
+	s := tx.ToURLSafe()
+	assert.NotEmpty(t, s)
+	assert.False(t, strings.ContainsAny(s, "+/="))
+	assert.Equal(t, s, url.QueryEscape(s))
+
+	link := "https://explorer.nebulas.io/tx/send?raw=" + s
+	u, err := url.Parse(link)
+	assert.Nil(t, err)
+	parsed, err := TransactionFromURLSafe(u.Query().Get("raw"))
+	assert.Nil(t, err)
+	assert.Equal(t, tx.Hash(), parsed.Hash())
+	assert.Equal(t, tx.sign, parsed.sign)
+	assert.Nil(t, parsed.VerifyIntegrity(1))
+	assert.Equal(t, s, parsed.ToURLSafe())
+
+	_, err = TransactionFromURLSafe(s + "=")
+	assert.NotNil(t, err)
+	_, err = TransactionFromURLSafe("!!")
+	assert.NotNil(t, err)
+}
@@ -0,0 +1,75 @@
+// Copyright (C) 2017 go-nebulas authors
+//
+// This file is part of the go-nebulas library.
+//
+// the go-nebulas library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// the go-nebulas library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with the go-nebulas library.  If not, see <http://www.gnu.org/licenses/>.
+//
+
+package core
+
+import (
+	"time"
+)
+
+// ValidFrom return the time the tx is valid from, zero time for no lower bound
+func (tx *Transaction) ValidFrom() time.Time {
+	return unixOrZero(tx.validFrom)
+}
+
+// Deadline return the time the tx is valid until, zero time for no upper bound
+func (tx *Transaction) Deadline() time.Time {
+	return unixOrZero(tx.deadline)
+}
+
+// SetValidityWindow set the window [validFrom, deadline] the tx is valid in, in unix seconds,
+// zero time disable the bound. both are hashed so the tx must be signed after.
+func (tx *Transaction) SetValidityWindow(validFrom, deadline time.Time) error {
+	if tx.frozen {
+		return ErrTransactionFrozen
+	}
+	from, until := zeroOrUnix(validFrom), zeroOrUnix(deadline)
+	if from != 0 && until != 0 && until < from {
+		return ErrInvalidArgument
+	}
+	tx.validFrom, tx.deadline = from, until
+	tx.rawBytes = nil
+	tx.cachedHash = nil
+	return nil
+}
+
+// ValidAt check t is in the validity window of tx, both bounds are inclusive.
+func (tx *Transaction) ValidAt(t time.Time) error {
+	sec := t.Unix()
+	if tx.validFrom != 0 && sec < tx.validFrom {
+		return ErrNotYetValid
+	}
+	if tx.deadline != 0 && sec > tx.deadline {
+		return ErrExpired
+	}
+	return nil
+}
+
+func unixOrZero(sec int64) time.Time {
+	if sec == 0 {
+		return time.Time{}
+	}
+	return time.Unix(sec, 0)
+}
+
+func zeroOrUnix(t time.Time) int64 {
+	if t.IsZero() {
+		return 0
+	}
+	return t.Unix()
+}
@@ -0,0 +1,105 @@
+// Copyright (C) 2017 go-nebulas authors
+//
+// This file is part of the go-nebulas library.
+//
+// the go-nebulas library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// the go-nebulas library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with the go-nebulas library.  If not, see <http://www.gnu.org/licenses/>.
+//
+
+package core
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTransaction_ValidAt(t *testing.T) {
+	validFrom := time.Unix(1500000000, 0)
+	deadline := validFrom.Add(time.Hour)
+
+	tests := []struct {
+		name      string
+		validFrom time.Time
+		deadline  time.Time
+		at        time.Time
+		err       error
+	}{
+		{"before validFrom", validFrom, deadline, validFrom.Add(-time.Second), ErrNotYetValid},
+		{"at validFrom", validFrom, deadline, validFrom, nil},
+		{"inside", validFrom, deadline, validFrom.Add(time.Minute), nil},
+		{"at deadline", validFrom, deadline, deadline, nil},
+		{"at deadline sub second", validFrom, deadline, deadline.Add(time.Millisecond), nil},
+		{"after deadline", validFrom, deadline, deadline.Add(time.Second), ErrExpired},
+		{"no validFrom", time.Time{}, deadline, time.Unix(1, 0), nil},
+		{"no deadline", validFrom, time.Time{}, validFrom.Add(1000 * time.Hour), nil},
+		{"no window", time.Time{}, time.Time{}, time.Unix(1, 0), nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tx := mockNormalTransaction(1, 1)
+			assert.Nil(t, tx.SetValidityWindow(tt.validFrom, tt.deadline))
+			assert.Equal(t, tt.validFrom.Unix(), tx.ValidFrom().Unix())
+			assert.Equal(t, tt.deadline.IsZero(), tx.Deadline().IsZero())
+			assert.Equal(t, tt.err, tx.ValidAt(tt.at))
+		})
+	}
+
+	tx := mockNormalTransaction(1, 1)
+	assert.Equal(t, ErrInvalidArgument, tx.SetValidityWindow(deadline, validFrom))
+}
+
+func TestTransaction_ValidityWindowSigned(t *testing.T) {
+	tx := mockNormalTransaction(1, 1)
+	plainHash, err := tx.calHash()
+	assert.Nil(t, err)
+
+	validFrom := time.Unix(1500000000, 0)
+	assert.Nil(t, tx.SetValidityWindow(validFrom, validFrom.Add(time.Hour)))
+	assert.Nil(t, signTx(tx))
+	assert.NotEqual(t, plainHash, tx.hash)
+	assert.Equal(t, TxHashV2, tx.HashVersion())
+
+	// the window is serialized
+	data, err := tx.Marshal()
+	assert.Nil(t, err)
+	decoded, err := UnmarshalTransaction(data)
+	assert.Nil(t, err)
+	assert.Nil(t, decoded.VerifyIntegrity(1))
+	assert.Equal(t, validFrom.Unix(), decoded.ValidFrom().Unix())
+	assert.Equal(t, ErrExpired, decoded.ValidAt(validFrom.Add(2*time.Hour)))
+
+	// and can't be widened after signing
+	decoded.deadline = 0
+	assert.Equal(t, ErrInvalidTransactionHash, decoded.VerifyIntegrity(1))
+}
+
+func TestTransaction_DeadlineCannotBeMoved(t *testing.T) {
+	deadline := time.Unix(1500003600, 0)
+	tx := mockNormalTransaction(1, 1)
+	assert.Nil(t, tx.SetValidityWindow(time.Time{}, deadline))
+	assert.Nil(t, signTx(tx))
+	assert.Nil(t, tx.VerifyIntegrity(1))
+
+	// a relay moving the deadline into another field would remove the expiry.
+	intoValidFrom := tx.Clone()
+	intoValidFrom.deadline, intoValidFrom.validFrom = 0, deadline.Unix()
+	assert.Nil(t, intoValidFrom.ValidAt(deadline.AddDate(1, 0, 0)))
+	assert.Equal(t, ErrInvalidTransactionHash, intoValidFrom.VerifyIntegrity(1))
+
+	intoPowNonce := tx.Clone()
+	intoPowNonce.deadline, intoPowNonce.powNonce = 0, uint64(deadline.Unix())
+	assert.Nil(t, intoPowNonce.ValidAt(deadline.AddDate(1, 0, 0)))
+	assert.Equal(t, ErrInvalidTransactionHash, intoPowNonce.VerifyIntegrity(1))
+}
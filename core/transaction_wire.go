@@ -0,0 +1,117 @@
+// Copyright (C) 2017 go-nebulas authors
+//
+// This file is part of the go-nebulas library.
+//
+// the go-nebulas library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// the go-nebulas library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with the go-nebulas library.  If not, see <http://www.gnu.org/licenses/>.
+//
+
+package core
+
+import (
+	"bytes"
+	"encoding/json"
+
+	"github.com/alexlisong/go-nebulas/core/pb"
+	"github.com/gogo/protobuf/proto"
+)
+
+// TxFormat is an encoding txs are received in.
+type TxFormat int
+
+// TxFormat
+const (
+	TxFormatProto   TxFormat = iota // protobuf wire bytes
+	TxFormatJSON                    // json of MarshalJSON
+	TxFormatURLSafe                 // base64url string of ToURLSafe
+)
+
+// NormalizeTransaction decode raw in format to the canonical instance of the tx, so the same tx from peers
+// using different formats dedups to equal instances. every format is checked by FromProto before the stored hash
+// is checked against the fields, and the received bytes are not kept, Marshal returns the canonical encoding.
+func NormalizeTransaction(raw []byte, format TxFormat) (*Transaction, error) {
+	var (
+		tx  *Transaction
+		err error
+	)
+	switch format {
+	case TxFormatProto:
+		tx, err = UnmarshalTransaction(raw)
+	case TxFormatJSON:
+		tx = new(Transaction)
+		err = json.Unmarshal(raw, tx)
+	case TxFormatURLSafe:
+		tx, err = TransactionFromURLSafe(string(raw))
+	default:
+		return nil, ErrInvalidArgument
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := tx.ValidateStoredHash(); err != nil {
+		return nil, err
+	}
+	tx.rawBytes = nil
+	return tx, nil
+}
+
+// UnmarshalTransaction parse a tx from wire bytes and keep the bytes,
+// so forwarding the tx sends exactly what was received.
+func UnmarshalTransaction(data []byte) (*Transaction, error) {
+	pbTx := new(corepb.Transaction)
+	if err := proto.Unmarshal(data, pbTx); err != nil {
+		return nil, err
+	}
+	tx := new(Transaction)
+	if err := tx.FromProto(pbTx); err != nil {
+		return nil, err
+	}
+	tx.rawBytes = make([]byte, len(data))
+	copy(tx.rawBytes, data)
+	return tx, nil
+}
+
+// UnmarshalTransactionStrict is UnmarshalTransaction for the consensus boundary, data must be the canonical
+// encoding of the tx, fields in order, no unknown or zero fields and minimal varints, so one tx has one byte form.
+func UnmarshalTransactionStrict(data []byte) (*Transaction, error) {
+	tx, err := UnmarshalTransaction(data)
+	if err != nil {
+		return nil, err
+	}
+	pbTx, err := tx.ToProto()
+	if err != nil {
+		return nil, err
+	}
+	canonical, err := proto.Marshal(pbTx)
+	if err != nil {
+		return nil, err
+	}
+	if !bytes.Equal(canonical, data) {
+		return nil, ErrNonCanonicalSerialization
+	}
+	return tx, nil
+}
+
+// Marshal return the wire bytes of tx, the original bytes if it was unmarshalled and not changed since.
+func (tx *Transaction) Marshal() ([]byte, error) {
+	if tx.rawBytes != nil {
+		data := make([]byte, len(tx.rawBytes))
+		copy(data, tx.rawBytes)
+		return data, nil
+	}
+	pbTx, err := tx.ToProto()
+	if err != nil {
+		return nil, err
+	}
+	return proto.Marshal(pbTx)
+}
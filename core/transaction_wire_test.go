@@ -0,0 +1,172 @@
+// Copyright (C) 2017 go-nebulas authors
+//
+// This file is part of the go-nebulas library.
+//
+// the go-nebulas library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// the go-nebulas library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with the go-nebulas library.  If not, see <http://www.gnu.org/licenses/>.
+//
+
+package core
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/alexlisong/go-nebulas/core/pb"
+	"github.com/gogo/protobuf/proto"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTransaction_MarshalRawBytes(t *testing.T) {
+	tx := mockNormalTransaction(1, 1)
+	assert.Nil(t, signTx(tx))
+	pbTx, err := tx.ToProto()
+	assert.Nil(t, err)
+	canonical, err := proto.Marshal(pbTx)
+	assert.Nil(t, err)
+	marshalled, err := tx.Marshal()
+	assert.Nil(t, err)
+	assert.Equal(t, canonical, marshalled)
+
+	// an unknown field is valid protobuf but dropped by re-marshalling.
+	input := append(append([]byte{}, canonical...), 0xf8, 0x06, 0x01) // field 111, varint 1
+	parsed, err := UnmarshalTransaction(input)
+	assert.Nil(t, err)
+	assert.Equal(t, tx.hash, parsed.hash)
+	assert.Nil(t, parsed.VerifyIntegrity(1))
+
+	forward, err := parsed.Marshal()
+	assert.Nil(t, err)
+	assert.Equal(t, input, forward)
+	input[0] ^= 0xff
+	assert.NotEqual(t, input, parsed.rawBytes)
+
+	// changed txs are marshalled again.
+	clone := parsed.Clone()
+	remarshalled, err := clone.Marshal()
+	assert.Nil(t, err)
+	assert.Equal(t, canonical, remarshalled)
+	parsed.SetTipRecipient(mockAddress())
+	assert.Nil(t, signTx(parsed))
+	changed, err := parsed.Marshal()
+	assert.Nil(t, err)
+	npbTx := new(corepb.Transaction)
+	assert.Nil(t, proto.Unmarshal(changed, npbTx))
+	assert.Equal(t, parsed.tipRecipient.Bytes(), npbTx.TipRecipient)
+
+	_, err = UnmarshalTransaction([]byte{0xff})
+	assert.NotNil(t, err)
+}
+
+func TestNormalizeTransaction(t *testing.T) {
+	tx := mockCallTransaction(1, 1, "totalSupply", "")
+	tx.SetTipRecipient(mockAddress())
+	assert.Nil(t, signTx(tx))
+
+	wire, err := tx.Marshal()
+	assert.Nil(t, err)
+	// unknown fields are dropped from the canonical instance
+	padded := append(append([]byte{}, wire...), 0xf8, 0x06, 0x01)
+	jsonData, err := json.Marshal(tx)
+	assert.Nil(t, err)
+
+	tests := []struct {
+		name   string
+		raw    []byte
+		format TxFormat
+	}{
+		{"proto", wire, TxFormatProto},
+		{"proto unknown field", padded, TxFormatProto},
+		{"json", jsonData, TxFormatJSON},
+		{"url safe", []byte(tx.ToURLSafe()), TxFormatURLSafe},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			normalized, err := NormalizeTransaction(tt.raw, tt.format)
+			assert.Nil(t, err)
+			assert.Equal(t, tx.Hash(), normalized.Hash())
+			assert.True(t, tx.Equals(normalized))
+			assert.Nil(t, normalized.VerifyIntegrity(1))
+			canonical, err := normalized.Marshal()
+			assert.Nil(t, err)
+			assert.Equal(t, wire, canonical)
+		})
+	}
+
+	_, err = NormalizeTransaction(wire, TxFormat(3))
+	assert.Equal(t, ErrInvalidArgument, err)
+
+	// a hash not matching the fields is rejected
+	forged := tx.Clone()
+	forged.nonce = 2
+	forgedJSON, err := json.Marshal(forged)
+	assert.Nil(t, err)
+	_, err = NormalizeTransaction(forgedJSON, TxFormatJSON)
+	assert.Equal(t, ErrHashMismatch, err)
+
+	// json missing addresses and amounts is rejected before hashing, whatever the hash algorithm.
+	for _, partial := range []string{
+		`{"nonce":"1","timestamp":"2020-01-01T00:00:00Z"}`,
+		`{"nonce":"1","timestamp":"2020-01-01T00:00:00Z","hashAlg":1}`,
+	} {
+		assert.NotPanics(t, func() {
+			_, err = NormalizeTransaction([]byte(partial), TxFormatJSON)
+		})
+		assert.NotNil(t, err)
+	}
+}
+
+func TestUnmarshalTransactionStrict(t *testing.T) {
+	tx := mockNormalTransaction(1, 1)
+	assert.Nil(t, signTx(tx))
+	canonical, err := tx.Marshal()
+	assert.Nil(t, err)
+	// field 1 hash, 32 bytes
+	assert.Equal(t, []byte{0x0a, 0x20}, canonical[:2])
+
+	parsed, err := UnmarshalTransactionStrict(canonical)
+	assert.Nil(t, err)
+	assert.True(t, tx.Equals(parsed))
+
+	join := func(parts ...[]byte) []byte {
+		var data []byte
+		for _, part := range parts {
+			data = append(data, part...)
+		}
+		return data
+	}
+	tests := []struct {
+		name string
+		data []byte
+	}{
+		{"unknown field", join(canonical, []byte{0xf8, 0x06, 0x01})},
+		{"fields out of order", join(canonical[34:], canonical[:34])},
+		{"non minimal length", join([]byte{0x0a, 0xa0, 0x00}, canonical[2:])},
+		{"zero field", join(canonical, []byte{0x80, 0x01, 0x00})}, // pow_nonce = 0
+		{"repeated field", join(canonical[:34], canonical)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// the same tx for the lenient decoder
+			lenient, err := UnmarshalTransaction(tt.data)
+			assert.Nil(t, err)
+			assert.True(t, tx.Equals(lenient))
+
+			_, err = UnmarshalTransactionStrict(tt.data)
+			assert.Equal(t, ErrNonCanonicalSerialization, err)
+		})
+	}
+
+	_, err = UnmarshalTransactionStrict([]byte{0xff})
+	assert.NotNil(t, err)
+}
@@ -94,34 +94,64 @@ var (
 	ErrInvalidChainID           = errors.New("invalid transaction chainID")
 	ErrInvalidTransactionSigner = errors.New("invalid transaction signer")
 	ErrInvalidTransactionHash   = errors.New("invalid transaction hash")
+	ErrHashMismatch             = errors.New("transaction hash mismatch")
 	ErrInvalidSignature         = errors.New("invalid transaction signature")
+	ErrInvalidPrivateKey        = errors.New("invalid private key")
+	ErrInvalidReplaySalt        = errors.New("invalid transaction replay salt, should be 32 bytes")
+	ErrInvalidBundleSigner      = errors.New("invalid bundle commitment signer")
+	ErrAlgorithmAddressMismatch = errors.New("transaction algorithm does not match the from address type")
+	ErrInvalidEphemeralPubKey   = errors.New("invalid transaction ephemeral public key")
+	ErrTransactionClockSkew     = errors.New("transaction timestamp is too far from local time")
+	ErrExpiredTransaction       = errors.New("transaction timestamp is older than the freshness window")
+	ErrFutureTransaction        = errors.New("transaction timestamp is in the future")
+	ErrNotYetValid              = errors.New("transaction is not valid yet")
+	ErrExpired                  = errors.New("transaction deadline has passed")
+	ErrWrongFork                = errors.New("transaction fork marker does not match the fork of the node")
+	ErrNonceOverflow            = errors.New("transaction nonce overflow")
+	ErrTransactionFrozen        = errors.New("transaction is signed and frozen, thaw it before changing hashed fields")
+	ErrNonceNotSigned           = errors.New("transaction nonce is not covered by its signature")
+	ErrAlgorithmNotInRuleset    = errors.New("transaction algorithm is not allowed by the ruleset")
+	ErrMissingReplaySalt        = errors.New("transaction replay salt is required by the ruleset")
+	ErrUnsupportedHashVersion   = errors.New("transaction hash version is not supported by the ruleset")
+	ErrUnsupportedHashAlgorithm = errors.New("unsupported transaction hash algorithm")
+	ErrInsecureAlgorithmPairing = errors.New("transaction hash and signature algorithm pairing is not allowed")
+	ErrNotMultiSend             = errors.New("transaction is not a multi-send")
+	ErrInvalidMultiSendOutputs  = errors.New("invalid multi-send outputs")
+	ErrStateDeltaNeedsExecution = errors.New("transaction needs the VM to compute its state delta")
 	ErrInvalidTxPayloadType     = errors.New("invalid transaction data payload type")
 	ErrInvalidGasPrice          = errors.New("invalid gas price, should be in (0, 10^12]")
 	ErrInvalidGasLimit          = errors.New("invalid gas limit, should be in (0, 5*10^10]")
 
 	ErrNoTimeToPackTransactions       = errors.New("no time left to pack transactions in a block")
 	ErrTxDataPayLoadOutOfMaxLength    = errors.New("data's payload is out of max data length")
+	ErrPreimageTooLarge               = errors.New("transaction hash preimage is out of max length")
 	ErrTxDataBinPayLoadOutOfMaxLength = errors.New("data's payload is out of max data length in a binary tx")
 	ErrNilArgument                    = errors.New("argument(s) is nil")
 	ErrInvalidArgument                = errors.New("invalid argument(s)")
 
 	ErrInsufficientBalance                = errors.New("insufficient balance")
 	ErrBelowGasPrice                      = errors.New("below the gas price")
+	ErrFeeTooLowForBaseFee                = errors.New("transaction gas price is below the base fee")
 	ErrGasCntOverflow                     = errors.New("the count of gas used is overflow")
 	ErrGasFeeOverflow                     = errors.New("the fee of gas used is overflow")
 	ErrInvalidTransfer                    = errors.New("transfer error: overflow or insufficient balance")
 	ErrGasLimitLessOrEqualToZero          = errors.New("gas limit less or equal to 0")
 	ErrOutOfGasLimit                      = errors.New("out of gas limit")
+	ErrGasLimitBelowIntrinsic             = errors.New("gas limit is below the intrinsic gas of transaction")
 	ErrTxExecutionFailed                  = errors.New("transaction execution failed")
 	ErrZeroGasPrice                       = errors.New("gas price should be greater than zero")
 	ErrZeroGasLimit                       = errors.New("gas limit should be greater than zero")
 	ErrContractDeployFailed               = errors.New("contract deploy failed")
 	ErrContractCheckFailed                = errors.New("contract check failed")
 	ErrContractTransactionAddressNotEqual = errors.New("contract transaction from-address not equal to to-address")
+	ErrNotContractSender                  = errors.New("transaction from-address is not a contract")
+	ErrCallToNonContract                  = errors.New("call transaction to-address has no contract code")
 
-	ErrDuplicatedTransaction = errors.New("duplicated transaction")
-	ErrSmallTransactionNonce = errors.New("cannot accept a transaction with smaller nonce")
-	ErrLargeTransactionNonce = errors.New("cannot accept a transaction with too bigger nonce")
+	ErrDuplicatedTransaction     = errors.New("duplicated transaction")
+	ErrNotReplacementTransaction = errors.New("transaction is not a replacement, sender or nonce not equal")
+	ErrInsufficientFeeBump       = errors.New("replacement transaction fee is lower than the original")
+	ErrSmallTransactionNonce     = errors.New("cannot accept a transaction with smaller nonce")
+	ErrLargeTransactionNonce     = errors.New("cannot accept a transaction with too bigger nonce")
 
 	ErrInvalidAddress         = errors.New("address: invalid address")
 	ErrInvalidAddressFormat   = errors.New("address: invalid address format")
@@ -145,6 +175,10 @@ var (
 	ErrInvalidProtoToBlockHeader = errors.New("protobuf message cannot be converted into BlockHeader")
 	ErrInvalidProtoToTransaction = errors.New("protobuf message cannot be converted into Transaction")
 	ErrInvalidTransactionData    = errors.New("invalid data in tx from Proto")
+	ErrNonCanonicalSerialization = errors.New("transaction bytes are not in canonical serialization")
+	ErrInvalidCBOR               = errors.New("invalid cbor transaction encoding")
+	ErrBatchTooLarge             = errors.New("batch of transactions is too large")
+	ErrTransactionTooLarge       = errors.New("transaction is too large")
 	ErrInvalidDagBlock           = errors.New("block's dag is incorrect")
 
 	ErrCannotRevertLIB        = errors.New("cannot revert latest irreversible block")
@@ -182,6 +216,32 @@ type TxPayload interface {
 	Execute(limitedGas *util.Uint128, tx *Transaction, block *Block, ws WorldState) (*util.Uint128, string, error)
 }
 
+// AddressPolicy flags recipients a tx should not be sent to, such as burn addresses.
+type AddressPolicy interface {
+	CheckRecipient(addr *Address) error
+}
+
+// CodeOracle tells if an address has contract code deployed, such as from the world state of the tail block.
+type CodeOracle interface {
+	HasCode(addr *Address) (bool, error)
+}
+
+// ContractSignatureValidator validates signs made on behalf of a contract wallet, such as calling its isValidSignature.
+type ContractSignatureValidator interface {
+	IsValid(addr *Address, hash byteutils.Hash, sign []byte) (bool, error)
+}
+
+// GasEstimator estimates the gas a tx would consume when executed, such as by a dry run on the tail block.
+type GasEstimator interface {
+	EstimateGas(tx *Transaction) (*util.Uint128, error)
+}
+
+// Signer signs tx hashes with a key it holds, such as a key in an HSM or a secure enclave.
+type Signer interface {
+	Public() keystore.PublicKey
+	Sign(hash byteutils.Hash) ([]byte, error)
+}
+
 // MessageType
 const (
 	MessageTypeNewBlock                   = "newblock"
@@ -0,0 +1,230 @@
+// Copyright (C) 2017 go-nebulas authors
+//
+// This file is part of the go-nebulas library.
+//
+// the go-nebulas library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// the go-nebulas library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with the go-nebulas library.  If not, see <http://www.gnu.org/licenses/>.
+//
+
+package core
+
+import (
+	"context"
+	"runtime"
+	"sync"
+
+	"github.com/alexlisong/go-nebulas/crypto"
+	"github.com/alexlisong/go-nebulas/crypto/keystore"
+	"github.com/alexlisong/go-nebulas/crypto/keystore/secp256k1"
+	"github.com/alexlisong/go-nebulas/util/byteutils"
+	"github.com/alexlisong/go-nebulas/util/logging"
+	"github.com/sirupsen/logrus"
+)
+
+// VerifyFrom verify the integrity of txs from index to the end.
+func (txs Transactions) VerifyFrom(index int, chainID uint32) error {
+	if index < 0 || index > len(txs) {
+		return ErrInvalidArgument
+	}
+	for _, tx := range txs[index:] {
+		if err := tx.VerifyIntegrity(chainID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// VerifyAll verify the integrity of txs on runtime.NumCPU() workers. the first failure cancels the
+// remaining work and is returned as a *TxHashError, when several txs fail before the workers stop
+// the one with the lowest index is reported. nil is only returned when every tx is verified.
+func (txs Transactions) VerifyAll(chainID uint32) error {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var (
+		mu        sync.Mutex
+		next      int
+		failedIdx int
+		failedErr error
+		wg        sync.WaitGroup
+	)
+	// take the next index to verify, -1 when there is no work left.
+	take := func() int {
+		mu.Lock()
+		defer mu.Unlock()
+		if next >= len(txs) {
+			return -1
+		}
+		next++
+		return next - 1
+	}
+	fail := func(i int, err error) {
+		mu.Lock()
+		if failedErr == nil || i < failedIdx {
+			failedIdx, failedErr = i, err
+		}
+		mu.Unlock()
+		cancel()
+	}
+
+	workers := runtime.NumCPU()
+	if workers > len(txs) {
+		workers = len(txs)
+	}
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				select {
+				case <-ctx.Done():
+					return
+				default:
+				}
+				i := take()
+				if i < 0 {
+					return
+				}
+				if err := txs[i].VerifyIntegrity(chainID); err != nil {
+					fail(i, err)
+				}
+			}
+		}()
+	}
+	wg.Wait()
+
+	if failedErr == nil {
+		return nil
+	}
+	logging.VLog().WithFields(logrus.Fields{
+		"index": failedIdx,
+		"tx":    txs[failedIdx],
+		"err":   failedErr,
+	}).Debug("Failed to verify tx in batch.")
+	return &TxHashError{Hash: txs[failedIdx].hash, Err: failedErr}
+}
+
+// VerifyWithKnownKeys is VerifyFrom(0, chainID) verifying the signs of senders in keys against their cached
+// public key instead of recovering it. keys is keyed by hex from address and must only hold keys
+// of verified txs of the sender, other senders are verified by recovery.
+func (txs Transactions) VerifyWithKnownKeys(chainID uint32, keys map[string]keystore.PublicKey) error {
+	for _, tx := range txs {
+		key, ok := keys[byteutils.Hex(tx.from.address)]
+		if !ok {
+			if err := tx.VerifyIntegrity(chainID); err != nil {
+				return err
+			}
+			continue
+		}
+		if err := tx.verifyWithKey(chainID, key); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// verifyWithKey is VerifyIntegrity with the sign verified against key of tx.from.
+func (tx *Transaction) verifyWithKey(chainID uint32, key keystore.PublicKey) error {
+	if err := tx.verifyChainAndHash(chainID); err != nil {
+		return err
+	}
+	if err := crypto.CheckAlgorithm(tx.alg); err != nil {
+		return err
+	}
+	if len(tx.sign) != secp256k1.RecoverableSignatureLength {
+		return ErrInvalidSignature
+	}
+	signature, err := crypto.NewSignature(tx.alg)
+	if err != nil {
+		return err
+	}
+	if err := signature.InitVerify(key); err != nil {
+		return err
+	}
+	ok, err := signature.Verify(tx.hash, tx.sign)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return ErrInvalidSignature
+	}
+	return nil
+}
+
+// VerificationProgress records how many txs of a block have been verified,
+// so an interrupted validation can be resumed instead of restarted.
+type VerificationProgress struct {
+	LastVerified int // index of the last verified tx, -1 if none
+}
+
+// NewVerificationProgress create a progress with no verified tx.
+func NewVerificationProgress() *VerificationProgress {
+	return &VerificationProgress{LastVerified: -1}
+}
+
+// Resume verify the txs after the last verified one, progress is updated after each tx passes.
+func (p *VerificationProgress) Resume(txs Transactions, chainID uint32) error {
+	if p.LastVerified < -1 || p.LastVerified >= len(txs) {
+		return ErrInvalidArgument
+	}
+	for i := p.LastVerified + 1; i < len(txs); i++ {
+		if err := txs[i].VerifyIntegrity(chainID); err != nil {
+			return err
+		}
+		p.LastVerified = i
+	}
+	return nil
+}
+
+// Done return if all the txs are verified.
+func (p *VerificationProgress) Done(txs Transactions) bool {
+	return p.LastVerified == len(txs)-1
+}
+
+// RecoverSender return the address of the signer public key, the hash and sign are not verified.
+func (tx *Transaction) RecoverSender() (*Address, error) {
+	pub, err := tx.SignerPublicKey()
+	if err != nil {
+		return nil, err
+	}
+	return NewAddressFromPublicKey(pub)
+}
+
+// RecoverSenders recover the sender of each tx on workers goroutines, runtime.NumCPU() if workers <= 0,
+// senders and errs are in the order of txs, it's cheaper than VerifyAll for indexers which only need the senders.
+func (txs Transactions) RecoverSenders(workers int) (senders []*Address, errs []error) {
+	senders = make([]*Address, len(txs))
+	errs = make([]error, len(txs))
+	if workers <= 0 {
+		workers = runtime.NumCPU()
+	}
+	indexes := make(chan int, len(txs))
+	for i := range txs {
+		indexes <- i
+	}
+	close(indexes)
+
+	var wg sync.WaitGroup
+	for w := 0; w < workers && w < len(txs); w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			// each index is taken by one worker, so the slots are written without lock.
+			for i := range indexes {
+				senders[i], errs[i] = txs[i].RecoverSender()
+			}
+		}()
+	}
+	wg.Wait()
+	return senders, errs
+}
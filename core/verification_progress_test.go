@@ -0,0 +1,216 @@
+// Copyright (C) 2017 go-nebulas authors
+//
+// This file is part of the go-nebulas library.
+//
+// the go-nebulas library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// the go-nebulas library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with the go-nebulas library.  If not, see <http://www.gnu.org/licenses/>.
+//
+
+package core
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/alexlisong/go-nebulas/crypto/keystore"
+	"github.com/alexlisong/go-nebulas/crypto/keystore/secp256k1"
+	"github.com/alexlisong/go-nebulas/util/byteutils"
+	"github.com/stretchr/testify/assert"
+)
+
+func mockSignedTransactions(t *testing.T, n int) Transactions {
+	var txs Transactions
+	for i := 1; i <= n; i++ {
+		tx := mockNormalTransaction(1, uint64(i))
+		assert.Nil(t, signTx(tx))
+		txs = append(txs, tx)
+	}
+	return txs
+}
+
+func TestTransactions_VerifyFrom(t *testing.T) {
+	txs := mockSignedTransactions(t, 5)
+	assert.Nil(t, txs.VerifyFrom(0, 1))
+	assert.Nil(t, txs.VerifyFrom(5, 1))
+	assert.Equal(t, ErrInvalidArgument, txs.VerifyFrom(6, 1))
+	assert.Equal(t, ErrInvalidArgument, txs.VerifyFrom(-1, 1))
+
+	txs[1].nonce = 100
+	assert.Equal(t, ErrInvalidTransactionHash, txs.VerifyFrom(0, 1))
+	// resuming after the broken tx skips it.
+	assert.Nil(t, txs.VerifyFrom(2, 1))
+}
+
+func TestVerificationProgress_Resume(t *testing.T) {
+	txs := mockSignedTransactions(t, 6)
+	txs[3].nonce = 100
+
+	progress := NewVerificationProgress()
+	assert.False(t, progress.Done(txs))
+	assert.Equal(t, ErrInvalidTransactionHash, progress.Resume(txs, 1))
+	assert.Equal(t, 2, progress.LastVerified)
+
+	// restore the tx, then resume from the checkpoint.
+	txs[3].nonce = 4
+	checkpoint := &VerificationProgress{LastVerified: progress.LastVerified}
+	assert.Nil(t, checkpoint.Resume(txs, 1))
+	assert.Equal(t, 5, checkpoint.LastVerified)
+	assert.True(t, checkpoint.Done(txs))
+
+	// resuming a finished progress is a no-op.
+	assert.Nil(t, checkpoint.Resume(txs, 1))
+	assert.Equal(t, ErrInvalidArgument, checkpoint.Resume(txs[:3], 1))
+}
+
+func TestTransactions_VerifyAll(t *testing.T) {
+	txs := mockSignedTransactions(t, 12)
+	assert.Nil(t, txs.VerifyAll(1))
+	assert.Nil(t, Transactions{}.VerifyAll(1))
+	err := txs.VerifyAll(2)
+	assert.True(t, errors.Is(err, ErrInvalidChainID))
+
+	// a mixed batch always fails, with the error and hash of one of the invalid txs.
+	txs[9].nonce++
+	txs[4].sign = nil
+	invalid := map[string]error{
+		txs[4].hash.String(): txs[4].VerifyIntegrity(1),
+		txs[9].hash.String(): txs[9].VerifyIntegrity(1),
+	}
+	for i := 0; i < 20; i++ {
+		err := txs.VerifyAll(1)
+		hashErr, ok := err.(*TxHashError)
+		assert.True(t, ok)
+		want, found := invalid[hashErr.Hash.String()]
+		assert.True(t, found)
+		assert.Equal(t, want, hashErr.Err)
+		assert.Contains(t, err.Error(), hashErr.Hash.String())
+	}
+
+	// the only invalid tx is reported whatever the scheduling.
+	txs = mockSignedTransactions(t, 12)
+	txs[11].nonce++
+	for i := 0; i < 20; i++ {
+		err := txs.VerifyAll(1)
+		assert.Equal(t, &TxHashError{Hash: txs[11].hash, Err: ErrInvalidTransactionHash}, err)
+		assert.True(t, errors.Is(err, ErrInvalidTransactionHash))
+	}
+}
+
+func mockBenchmarkTransactions(b *testing.B) Transactions {
+	txs := make(Transactions, 200)
+	for i := range txs {
+		txs[i] = mockNormalTransaction(1, uint64(i+1))
+		if err := signTx(txs[i]); err != nil {
+			b.Fatal(err)
+		}
+	}
+	return txs
+}
+
+func benchmarkVerifyTransactions(b *testing.B, verify func(txs Transactions) error) {
+	txs := mockBenchmarkTransactions(b)
+	b.ResetTimer()
+	for n := 0; n < b.N; n++ {
+		if err := verify(txs); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkTransactions_VerifySerial(b *testing.B) {
+	benchmarkVerifyTransactions(b, func(txs Transactions) error { return txs.VerifyFrom(0, 1) })
+}
+
+func BenchmarkTransactions_VerifyAll(b *testing.B) {
+	benchmarkVerifyTransactions(b, func(txs Transactions) error { return txs.VerifyAll(1) })
+}
+
+func TestTransactions_RecoverSenders(t *testing.T) {
+	txs := mockSignedTransactions(t, 6)
+	txs[3].sign = nil
+
+	senders, errs := txs.RecoverSenders(3)
+	assert.Equal(t, len(txs), len(senders))
+	assert.Equal(t, len(txs), len(errs))
+	for i, tx := range txs {
+		if i == 3 {
+			assert.NotNil(t, errs[i])
+			assert.Nil(t, senders[i])
+			continue
+		}
+		assert.Nil(t, errs[i])
+		assert.True(t, tx.from.Equals(senders[i]))
+	}
+
+	senders, errs = Transactions{}.RecoverSenders(0)
+	assert.Empty(t, senders)
+	assert.Empty(t, errs)
+}
+
+func BenchmarkTransactions_RecoverSenders(b *testing.B) {
+	benchmarkVerifyTransactions(b, func(txs Transactions) error {
+		_, errs := txs.RecoverSenders(0)
+		for _, err := range errs {
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func knownKeys(txs Transactions) map[string]keystore.PublicKey {
+	keys := make(map[string]keystore.PublicKey)
+	for _, tx := range txs {
+		pub, _ := tx.SignerPublicKey()
+		keys[byteutils.Hex(tx.from.address)] = secp256k1.NewPublicKey(pub)
+	}
+	return keys
+}
+
+func TestTransactions_VerifyWithKnownKeys(t *testing.T) {
+	txs := mockSignedTransactions(t, 6)
+	// senders of even txs are known
+	keys := knownKeys(Transactions{txs[0], txs[2], txs[4]})
+	assert.Nil(t, txs.VerifyWithKnownKeys(1, keys))
+	assert.Nil(t, txs.VerifyWithKnownKeys(1, nil))
+	assert.Equal(t, ErrInvalidChainID, txs.VerifyWithKnownKeys(2, keys))
+
+	// a sign not made by the known key
+	forged := txs[2].Clone()
+	forged.sign = txs[0].sign
+	assert.Equal(t, ErrInvalidSignature, Transactions{forged}.VerifyWithKnownKeys(1, keys))
+	assert.NotNil(t, Transactions{forged}.VerifyWithKnownKeys(1, nil))
+
+	// a key cached for the wrong sender
+	wrong := map[string]keystore.PublicKey{byteutils.Hex(txs[1].from.address): keys[byteutils.Hex(txs[0].from.address)]}
+	assert.Equal(t, ErrInvalidSignature, txs[1:2].VerifyWithKnownKeys(1, wrong))
+
+	tampered := txs[4].Clone()
+	tampered.nonce++
+	assert.Equal(t, ErrInvalidTransactionHash, Transactions{tampered}.VerifyWithKnownKeys(1, keys))
+	short := txs[4].Clone()
+	short.sign = short.sign[:10]
+	assert.Equal(t, ErrInvalidSignature, Transactions{short}.VerifyWithKnownKeys(1, keys))
+}
+
+func BenchmarkTransactions_VerifyWithKnownKeys(b *testing.B) {
+	txs := mockBenchmarkTransactions(b)
+	keys := knownKeys(txs)
+	b.ResetTimer()
+	for n := 0; n < b.N; n++ {
+		if err := txs.VerifyWithKnownKeys(1, keys); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
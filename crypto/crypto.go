@@ -63,12 +63,24 @@ func NewSignature(alg keystore.Algorithm) (keystore.Signature, error) {
 	}
 }
 
+// supportedAlgorithms is the compiled-in set of signature algorithms
+var supportedAlgorithms = []keystore.Algorithm{
+	keystore.SECP256K1,
+}
+
+// SupportedAlgorithms returns the Algorithms supported for sign and verify
+func SupportedAlgorithms() []keystore.Algorithm {
+	algs := make([]keystore.Algorithm, len(supportedAlgorithms))
+	copy(algs, supportedAlgorithms)
+	return algs
+}
+
 // CheckAlgorithm check if support the input Algorithm
 func CheckAlgorithm(alg keystore.Algorithm) error {
-	switch alg {
-	case keystore.SECP256K1:
-		return nil
-	default:
-		return ErrAlgorithmInvalid
+	for _, v := range supportedAlgorithms {
+		if v == alg {
+			return nil
+		}
 	}
+	return ErrAlgorithmInvalid
 }
@@ -0,0 +1,41 @@
+// Copyright (C) 2017 go-nebulas authors
+//
+// This file is part of the go-nebulas library.
+//
+// the go-nebulas library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// the go-nebulas library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with the go-nebulas library.  If not, see <http://www.gnu.org/licenses/>.
+//
+
+package crypto
+
+import (
+	"testing"
+
+	"github.com/alexlisong/go-nebulas/crypto/keystore"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSupportedAlgorithms(t *testing.T) {
+	algs := SupportedAlgorithms()
+	assert.Contains(t, algs, keystore.SECP256K1)
+	for _, alg := range algs {
+		assert.Nil(t, CheckAlgorithm(alg))
+	}
+
+	// returned slice is a copy
+	algs[0] = keystore.Algorithm(0)
+	assert.Nil(t, CheckAlgorithm(keystore.SECP256K1))
+
+	assert.Equal(t, ErrAlgorithmInvalid, CheckAlgorithm(keystore.Algorithm(0)))
+	assert.Equal(t, ErrAlgorithmInvalid, CheckAlgorithm(keystore.Algorithm(255)))
+}
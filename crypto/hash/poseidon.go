@@ -0,0 +1,201 @@
+// Copyright (C) 2017 go-nebulas authors
+//
+// This file is part of the go-nebulas library.
+//
+// the go-nebulas library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// the go-nebulas library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with the go-nebulas library.  If not, see <http://www.gnu.org/licenses/>.
+//
+
+package hash
+
+import (
+	"errors"
+	"math/big"
+	"sync"
+)
+
+// Poseidon parameters of circomlib for 2 inputs: BN254 scalar field, width 3, x^5 s-box,
+// 8 full rounds and 57 partial rounds.
+const (
+	poseidonWidth         = 3
+	poseidonRate          = poseidonWidth - 1
+	poseidonFullRounds    = 8
+	poseidonPartialRounds = 57
+	poseidonFieldBits     = 254
+)
+
+// PoseidonElementSize is the byte size of a Poseidon field element.
+const PoseidonElementSize = 32
+
+var (
+	// ErrInvalidFieldElement is returned when a Poseidon input is not lower than the field modulus.
+	ErrInvalidFieldElement = errors.New("input is not an element of the poseidon field")
+
+	// poseidonModulus is the BN254 scalar field modulus.
+	poseidonModulus, _ = new(big.Int).SetString("21888242871839275222246405745257275088548364400416034343698204186575808495617", 10)
+
+	poseidonOnce      sync.Once
+	poseidonConstants []*big.Int
+	poseidonMDS       [poseidonWidth][poseidonWidth]*big.Int
+)
+
+// Poseidon returns the Poseidon digest of the field elements, each arg is a big-endian integer
+// lower than the BN254 scalar field modulus. The elements are absorbed 2 at a time into a sponge
+// over the circomlib permutation after padding them with a 1 element and zero elements to a multiple of 2,
+// the digest is the first state element in PoseidonElementSize bytes big-endian.
+func Poseidon(args ...[]byte) ([]byte, error) {
+	inputs := make([]*big.Int, 0, len(args)+poseidonRate)
+	for _, arg := range args {
+		v := new(big.Int).SetBytes(arg)
+		if v.Cmp(poseidonModulus) >= 0 {
+			return nil, ErrInvalidFieldElement
+		}
+		inputs = append(inputs, v)
+	}
+	inputs = append(inputs, big.NewInt(1))
+	for len(inputs)%poseidonRate != 0 {
+		inputs = append(inputs, new(big.Int))
+	}
+
+	state := make([]*big.Int, poseidonWidth)
+	for i := range state {
+		state[i] = new(big.Int)
+	}
+	for start := 0; start < len(inputs); start += poseidonRate {
+		for i := 0; i < poseidonRate; i++ {
+			state[i+1].Add(state[i+1], inputs[start+i])
+			state[i+1].Mod(state[i+1], poseidonModulus)
+		}
+		state = poseidonPermute(state)
+	}
+
+	digest := make([]byte, PoseidonElementSize)
+	b := state[0].Bytes()
+	copy(digest[PoseidonElementSize-len(b):], b)
+	return digest, nil
+}
+
+// poseidonPermute applies the Poseidon permutation to state, the same as circomlib's poseidon,
+// poseidon([a, b]) of circomlib is poseidonPermute([0, a, b])[0].
+func poseidonPermute(state []*big.Int) []*big.Int {
+	poseidonOnce.Do(initPoseidonParameters)
+
+	five := big.NewInt(5)
+	for r := 0; r < poseidonFullRounds+poseidonPartialRounds; r++ {
+		for i := range state {
+			state[i].Add(state[i], poseidonConstants[r*poseidonWidth+i])
+			state[i].Mod(state[i], poseidonModulus)
+		}
+		if r < poseidonFullRounds/2 || r >= poseidonFullRounds/2+poseidonPartialRounds {
+			for i := range state {
+				state[i].Exp(state[i], five, poseidonModulus)
+			}
+		} else {
+			state[0].Exp(state[0], five, poseidonModulus)
+		}
+
+		mixed := make([]*big.Int, poseidonWidth)
+		for i := range mixed {
+			mixed[i] = new(big.Int)
+			for j := range state {
+				mixed[i].Add(mixed[i], new(big.Int).Mul(poseidonMDS[i][j], state[j]))
+			}
+			mixed[i].Mod(mixed[i], poseidonModulus)
+		}
+		state = mixed
+	}
+	return state
+}
+
+// initPoseidonParameters derives the round constants and the cauchy MDS matrix from the Grain LFSR
+// as in the reference generate_parameters_grain script of the Poseidon paper.
+func initPoseidonParameters() {
+	grain := newGrainLFSR()
+
+	poseidonConstants = make([]*big.Int, (poseidonFullRounds+poseidonPartialRounds)*poseidonWidth)
+	for i := range poseidonConstants {
+		c := grain.nextInt(poseidonFieldBits)
+		for c.Cmp(poseidonModulus) >= 0 {
+			c = grain.nextInt(poseidonFieldBits)
+		}
+		poseidonConstants[i] = c
+	}
+
+	var xs, ys [poseidonWidth]*big.Int
+	for i := range xs {
+		xs[i] = grain.nextInt(poseidonFieldBits)
+		xs[i].Mod(xs[i], poseidonModulus)
+	}
+	for i := range ys {
+		ys[i] = grain.nextInt(poseidonFieldBits)
+		ys[i].Mod(ys[i], poseidonModulus)
+	}
+	for i := range xs {
+		for j := range ys {
+			sum := new(big.Int).Add(xs[i], ys[j])
+			poseidonMDS[i][j] = sum.ModInverse(sum.Mod(sum, poseidonModulus), poseidonModulus)
+		}
+	}
+}
+
+// grainLFSR is the self-shrinking 80 bits Grain LFSR generating the Poseidon parameters.
+type grainLFSR struct {
+	bits []byte
+}
+
+func newGrainLFSR() *grainLFSR {
+	g := &grainLFSR{}
+	g.push(1, 2) // prime field
+	g.push(0, 4) // x^alpha s-box
+	g.push(poseidonFieldBits, 12)
+	g.push(poseidonWidth, 12)
+	g.push(poseidonFullRounds, 10)
+	g.push(poseidonPartialRounds, 10)
+	for i := 0; i < 30; i++ {
+		g.bits = append(g.bits, 1)
+	}
+	for i := 0; i < 160; i++ {
+		g.update()
+	}
+	return g
+}
+
+func (g *grainLFSR) push(v, size int) {
+	for i := size - 1; i >= 0; i-- {
+		g.bits = append(g.bits, byte(v>>uint(i))&1)
+	}
+}
+
+func (g *grainLFSR) update() byte {
+	b := g.bits[62] ^ g.bits[51] ^ g.bits[38] ^ g.bits[23] ^ g.bits[13] ^ g.bits[0]
+	g.bits = append(g.bits[1:], b)
+	return b
+}
+
+// nextBit output the second bit of each pair whose first bit is 1.
+func (g *grainLFSR) nextBit() byte {
+	for g.update() == 0 {
+		g.update()
+	}
+	return g.update()
+}
+
+// nextInt return the big-endian integer of the next size bits.
+func (g *grainLFSR) nextInt(size int) *big.Int {
+	v := new(big.Int)
+	for i := 0; i < size; i++ {
+		v.Lsh(v, 1)
+		v.SetBit(v, 0, uint(g.nextBit()))
+	}
+	return v
+}
@@ -0,0 +1,74 @@
+// Copyright (C) 2017 go-nebulas authors
+//
+// This file is part of the go-nebulas library.
+//
+// the go-nebulas library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// the go-nebulas library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with the go-nebulas library.  If not, see <http://www.gnu.org/licenses/>.
+//
+
+package hash
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPoseidonPermute(t *testing.T) {
+	// poseidon([1, 2]) of circomlib
+	want, _ := new(big.Int).SetString("7853200120776062878684798364095072458815029376092732009249414926327459813530", 10)
+	state := poseidonPermute([]*big.Int{big.NewInt(0), big.NewInt(1), big.NewInt(2)})
+	assert.Equal(t, want, state[0])
+
+	// first round constant and MDS entry of circomlib
+	assert.Equal(t, "ee9a592ba9a9518d05986d656f40c2114c4993c11bb29938d21d47304cd8e6e", poseidonConstants[0].Text(16))
+	assert.Equal(t, "109b7f411ba0e4c9b2b70caf5c36a7b194be7c11ad24378bfedb68592ba8118b", poseidonMDS[0][0].Text(16))
+}
+
+func TestPoseidon(t *testing.T) {
+	one := []byte{1}
+	two := []byte{2}
+	tests := []struct {
+		name string
+		args [][]byte
+	}{
+		{"empty", nil},
+		{"one", [][]byte{one}},
+		{"one zero", [][]byte{one, {0}}},
+		{"one two", [][]byte{one, two}},
+		{"two one", [][]byte{two, one}},
+		{"odd", [][]byte{one, two, one}},
+		{"max", [][]byte{new(big.Int).Sub(poseidonModulus, big.NewInt(1)).Bytes()}},
+	}
+
+	digests := make(map[string]string)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			digest, err := Poseidon(tt.args...)
+			assert.Nil(t, err)
+			assert.Equal(t, PoseidonElementSize, len(digest))
+			assert.True(t, new(big.Int).SetBytes(digest).Cmp(poseidonModulus) < 0)
+
+			again, _ := Poseidon(tt.args...)
+			assert.Equal(t, digest, again)
+
+			_, ok := digests[string(digest)]
+			assert.False(t, ok, "padding must keep inputs apart")
+			digests[string(digest)] = tt.name
+		})
+	}
+
+	_, err := Poseidon(one, poseidonModulus.Bytes())
+	assert.Equal(t, ErrInvalidFieldElement, err)
+}
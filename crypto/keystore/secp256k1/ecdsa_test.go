@@ -105,3 +105,48 @@ func TestSign(t *testing.T) {
 		}
 	}
 }
+
+func TestRecoverableSignature(t *testing.T) {
+	msg := hash.Sha3256([]byte("nebulas"))
+	priv, err := NewECDSAPrivateKey()
+	assert.Nil(t, err)
+	privData, err := FromECDSAPrivateKey(priv)
+	assert.Nil(t, err)
+	sig, err := Sign(msg, privData)
+	assert.Nil(t, err)
+
+	r, s, v, err := DecodeRecoverableSignature(sig)
+	assert.Nil(t, err)
+	assert.Equal(t, sig, EncodeRecoverableSignature(r, s, v))
+
+	// short R/S are left padded.
+	encoded := EncodeRecoverableSignature([]byte{1}, []byte{2, 3}, 1)
+	assert.Equal(t, RecoverableSignatureLength, len(encoded))
+	r, s, v, err = DecodeRecoverableSignature(encoded)
+	assert.Nil(t, err)
+	assert.Equal(t, append(make([]byte, 31), 1), r)
+	assert.Equal(t, append(make([]byte, 30), 2, 3), s)
+	assert.Equal(t, byte(1), v)
+	assert.Nil(t, EncodeRecoverableSignature(make([]byte, 33), s, v))
+}
+
+func TestRecoverableSignature_Malformed(t *testing.T) {
+	tests := []struct {
+		name string
+		sig  []byte
+	}{
+		{"nil", nil},
+		{"short", make([]byte, RecoverableSignatureLength-1)},
+		{"long", make([]byte, RecoverableSignatureLength+1)},
+		{"invalid recovery id", append(make([]byte, RecoverableSignatureLength-1), 4)},
+	}
+	msg := hash.Sha3256([]byte("nebulas"))
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, _, _, err := DecodeRecoverableSignature(tt.sig)
+			assert.Equal(t, ErrInvalidSignature, err)
+			_, err = RecoverECDSAPublicKey(msg, tt.sig)
+			assert.Equal(t, ErrInvalidSignature, err)
+		})
+	}
+}
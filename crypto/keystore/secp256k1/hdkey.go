@@ -0,0 +1,136 @@
+// Copyright (C) 2017 go-nebulas authors
+//
+// This file is part of the go-nebulas library.
+//
+// the go-nebulas library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// the go-nebulas library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with the go-nebulas library.  If not, see <http://www.gnu.org/licenses/>.
+//
+
+// hd key derivation, see https://github.com/bitcoin/bips/blob/master/bip-0032.mediawiki
+
+package secp256k1
+
+import (
+	"crypto/hmac"
+	"crypto/sha512"
+	"encoding/binary"
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+const (
+	// HardenedKeyStart is the index of the first hardened child key
+	HardenedKeyStart uint32 = 0x80000000
+
+	minHDSeedLen = 16
+	maxHDSeedLen = 64
+)
+
+var hdMasterKey = []byte("Bitcoin seed")
+
+// ParseHDPath parse a derivation path like m/44'/2718'/0'/0/0 to child indexes,
+// hardened indexes are marked with ' or h.
+func ParseHDPath(path string) ([]uint32, error) {
+	parts := strings.Split(path, "/")
+	if parts[0] != "m" {
+		return nil, ErrInvalidHDPath
+	}
+	indexes := make([]uint32, 0, len(parts)-1)
+	for _, part := range parts[1:] {
+		hardened := strings.HasSuffix(part, "'") || strings.HasSuffix(part, "h")
+		if hardened {
+			part = part[:len(part)-1]
+		}
+		// digits only, strconv accepts a leading sign
+		if len(part) == 0 || part[0] < '0' || part[0] > '9' {
+			return nil, ErrInvalidHDPath
+		}
+		index, err := strconv.ParseUint(part, 10, 32)
+		if err != nil || uint32(index) >= HardenedKeyStart {
+			return nil, ErrInvalidHDPath
+		}
+		if hardened {
+			index += uint64(HardenedKeyStart)
+		}
+		indexes = append(indexes, uint32(index))
+	}
+	return indexes, nil
+}
+
+// DeriveHDPrivateKey derive the private key at path from the hd seed.
+func DeriveHDPrivateKey(seed []byte, path string) ([]byte, error) {
+	if len(seed) < minHDSeedLen || len(seed) > maxHDSeedLen {
+		return nil, ErrInvalidHDSeed
+	}
+	indexes, err := ParseHDPath(path)
+	if err != nil {
+		return nil, err
+	}
+
+	mac := hmac.New(sha512.New, hdMasterKey)
+	mac.Write(seed)
+	I := mac.Sum(nil)
+	key, chainCode := I[:32], I[32:]
+	if !SeckeyVerify(key) {
+		return nil, ErrInvalidPrivateKey
+	}
+
+	for _, index := range indexes {
+		key, chainCode, err = deriveHDChild(key, chainCode, index)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return key, nil
+}
+
+func deriveHDChild(key, chainCode []byte, index uint32) ([]byte, []byte, error) {
+	data := make([]byte, 0, 37)
+	if index >= HardenedKeyStart {
+		data = append(data, 0x0)
+		data = append(data, key...)
+	} else {
+		pub, err := GetPublicKey(key)
+		if err != nil {
+			return nil, nil, err
+		}
+		data = append(data, compressPublicKey(pub)...)
+	}
+	var indexBytes [4]byte
+	binary.BigEndian.PutUint32(indexBytes[:], index)
+	data = append(data, indexBytes[:]...)
+
+	mac := hmac.New(sha512.New, chainCode)
+	mac.Write(data)
+	I := mac.Sum(nil)
+	il := new(big.Int).SetBytes(I[:32])
+	n := S256().Params().N
+	if il.Cmp(n) >= 0 {
+		return nil, nil, ErrInvalidPrivateKey
+	}
+	child := il.Add(il, new(big.Int).SetBytes(key))
+	child.Mod(child, n)
+	if child.Sign() == 0 {
+		return nil, nil, ErrInvalidPrivateKey
+	}
+	return paddedBigBytes(child, 32), I[32:], nil
+}
+
+// compressPublicKey convert a 65 bytes uncompressed public key to 33 bytes.
+func compressPublicKey(pub []byte) []byte {
+	compressed := make([]byte, 33)
+	compressed[0] = 0x2 + pub[64]&0x1
+	copy(compressed[1:], pub[1:33])
+	return compressed
+}
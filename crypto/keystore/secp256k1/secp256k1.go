@@ -69,6 +69,12 @@ var (
 
 	// ErrRecoverFailed recover failed
 	ErrRecoverFailed = errors.New("recovery failed")
+
+	// ErrInvalidHDSeed invalid hd seed length
+	ErrInvalidHDSeed = errors.New("invalid hd seed, need 16 to 64 bytes")
+
+	// ErrInvalidHDPath invalid hd derivation path
+	ErrInvalidHDPath = errors.New("invalid hd derivation path")
 )
 
 var ctx *C.secp256k1_context
@@ -123,15 +129,16 @@ func RecoverECDSAPublicKey(msg []byte, signature []byte) ([]byte, error) {
 	if len(msg) != 32 {
 		return nil, ErrInvalidMsgLen
 	}
-	if len(signature) != 65 {
-		return nil, ErrInvalidSignature
+	_, _, recid, err := DecodeRecoverableSignature(signature)
+	if err != nil {
+		return nil, err
 	}
 	var (
 		sig    C.secp256k1_ecdsa_recoverable_signature
 		pubkey C.secp256k1_pubkey
 	)
 
-	result := int(C.secp256k1_ecdsa_recoverable_signature_parse_compact(ctx, &sig, (*C.uchar)(unsafe.Pointer(&signature[0])), (C.int(signature[64]))))
+	result := int(C.secp256k1_ecdsa_recoverable_signature_parse_compact(ctx, &sig, (*C.uchar)(unsafe.Pointer(&signature[0])), (C.int(recid))))
 	if result != 1 {
 		return nil, ErrRecoverFailed
 	}
@@ -166,12 +173,12 @@ func Sign(msg []byte, seckey []byte) ([]byte, error) {
 	}
 
 	var (
-		sig   = make([]byte, 65)
+		sig   = make([]byte, 2*scalarLength)
 		recid C.int
 	)
 	C.secp256k1_ecdsa_recoverable_signature_serialize_compact(ctx, cBuf(sig), &recid, &sigstruct)
-	sig[64] = byte(recid) // add back recid to get 65 bytes sig
-	return sig, nil
+	// add back recid to get 65 bytes sig
+	return EncodeRecoverableSignature(sig[:scalarLength], sig[scalarLength:], byte(recid)), nil
 }
 
 // Verify verify with public key
@@ -24,6 +24,42 @@ import (
 	"github.com/alexlisong/go-nebulas/crypto/keystore"
 )
 
+const (
+	// RecoverableSignatureLength length of recoverable signature [R || S || V]
+	RecoverableSignatureLength = 65
+
+	// scalarLength length of R and S in recoverable signature
+	scalarLength = 32
+
+	// maxRecoveryID max recovery id V in recoverable signature
+	maxRecoveryID = 3
+)
+
+// EncodeRecoverableSignature encode signature to the canonical 65 bytes [R || S || V],
+// R and S are left padded to 32 bytes, nil is returned if R or S is longer than 32 bytes.
+func EncodeRecoverableSignature(r, s []byte, v byte) []byte {
+	if len(r) > scalarLength || len(s) > scalarLength {
+		return nil
+	}
+	sig := make([]byte, RecoverableSignatureLength)
+	copy(sig[scalarLength-len(r):scalarLength], r)
+	copy(sig[2*scalarLength-len(s):2*scalarLength], s)
+	sig[2*scalarLength] = v
+	return sig
+}
+
+// DecodeRecoverableSignature decode the canonical 65 bytes [R || S || V] signature
+func DecodeRecoverableSignature(sig []byte) (r, s []byte, v byte, err error) {
+	if len(sig) != RecoverableSignatureLength {
+		return nil, nil, 0, ErrInvalidSignature
+	}
+	v = sig[2*scalarLength]
+	if v > maxRecoveryID {
+		return nil, nil, 0, ErrInvalidSignature
+	}
+	return sig[:scalarLength], sig[scalarLength : 2*scalarLength], v, nil
+}
+
 // Signature signature ecdsa
 type Signature struct {
 	privateKey *PrivateKey